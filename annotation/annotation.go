@@ -0,0 +1,203 @@
+package annotation
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Annotation 文档注释中的一个注解，例如 // @Repository(table="user", cache=true)。
+// 所有位置都是在这一行中的字节偏移
+type Annotation struct {
+	Name    string            // 带 @，例如 @Repository
+	Args    map[string]string // 去掉引号、处理转义后的参数
+	List    []Arg             // 按出现顺序的参数
+	Pos     int               // @ 的位置
+	NameEnd int
+	End     int // 注解结束的位置(右括号之后)，没有括号时等于 NameEnd
+}
+
+type Arg struct {
+	Key      string
+	Value    string
+	Quoted   bool
+	KeyPos   int
+	ValuePos int // 值的起始位置，带引号时指向左引号
+	ValueEnd int
+}
+
+// SyntaxError 注解格式错误，Offset 为出错的字节偏移
+type SyntaxError struct {
+	Offset int
+	Msg    string
+}
+
+func (e *SyntaxError) Error() string {
+	return fmt.Sprintf("col %d: %s", e.Offset+1, e.Msg)
+}
+
+// Find 在 docLines 中查找名为 name(带 @)的注解
+func Find(docLines []string, name string) (Annotation, bool) {
+	for _, line := range docLines {
+		if a, ok := ParseLine(line); ok && a.Name == name {
+			return a, true
+		}
+	}
+	return Annotation{}, false
+}
+
+// Has 判断 docLines 中是否有名为 name 的注解
+func Has(docLines []string, name string) bool {
+	_, ok := Find(docLines, name)
+	return ok
+}
+
+// ParseLine 解析一行注释，不是注解时返回 false；格式错误时返回已经解析出的部分
+func ParseLine(line string) (Annotation, bool) {
+	a, ok, _ := Parse(line)
+	return a, ok
+}
+
+// Parse 解析一行注释 "// @Name(k="v", k2=v2)"，不是注解时返回 false
+func Parse(line string) (Annotation, bool, error) {
+	s := &scanner{line: line}
+	s.skipSpace()
+	if !strings.HasPrefix(line[s.pos:], "//") {
+		return Annotation{}, false, nil
+	}
+	s.pos += 2
+	s.skipSpace()
+	if s.peek() != '@' {
+		return Annotation{}, false, nil
+	}
+	a := Annotation{Pos: s.pos, Args: map[string]string{}}
+	s.pos++
+	if s.ident() == "" {
+		return Annotation{}, false, nil
+	}
+	a.Name = line[a.Pos:s.pos]
+	a.NameEnd, a.End = s.pos, s.pos
+	if s.peek() != '(' {
+		return a, true, nil
+	}
+	s.pos++
+	err := s.args(&a)
+	a.End = s.pos
+	return a, true, err
+}
+
+type scanner struct {
+	line string
+	pos  int
+}
+
+func (s *scanner) peek() rune {
+	if s.pos >= len(s.line) {
+		return 0
+	}
+	r, _ := utf8.DecodeRuneInString(s.line[s.pos:])
+	return r
+}
+
+func (s *scanner) skipSpace() {
+	for s.pos < len(s.line) && (s.line[s.pos] == ' ' || s.line[s.pos] == '\t') {
+		s.pos++
+	}
+}
+
+func (s *scanner) ident() string {
+	start := s.pos
+	for s.pos < len(s.line) {
+		r, size := utf8.DecodeRuneInString(s.line[s.pos:])
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '_' && r != '.' {
+			break
+		}
+		s.pos += size
+	}
+	return s.line[start:s.pos]
+}
+
+func (s *scanner) errorf(offset int, format string, args ...any) error {
+	return &SyntaxError{Offset: offset, Msg: fmt.Sprintf(format, args...)}
+}
+
+// args 解析括号内的 k=v 列表，直到右括号
+func (s *scanner) args(a *Annotation) error {
+	for {
+		s.skipSpace()
+		switch s.peek() {
+		case ')':
+			s.pos++
+			return nil
+		case 0:
+			return s.errorf(s.pos, "missing )")
+		}
+		arg := Arg{KeyPos: s.pos}
+		if arg.Key = s.ident(); arg.Key == "" {
+			return s.errorf(s.pos, "expected key, found %q", s.peek())
+		}
+		s.skipSpace()
+		if s.peek() != '=' {
+			return s.errorf(s.pos, "expected = after %s", arg.Key)
+		}
+		s.pos++
+		s.skipSpace()
+		arg.ValuePos = s.pos
+		if s.peek() == '"' {
+			v, err := s.quoted()
+			if err != nil {
+				return err
+			}
+			arg.Value, arg.Quoted = v, true
+		} else {
+			for s.pos < len(s.line) && !strings.ContainsRune(",) \t\"", rune(s.line[s.pos])) {
+				s.pos++
+			}
+			if arg.Value = s.line[arg.ValuePos:s.pos]; arg.Value == "" {
+				return s.errorf(s.pos, "missing value for %s", arg.Key)
+			}
+		}
+		arg.ValueEnd = s.pos
+		if _, ok := a.Args[arg.Key]; ok {
+			return s.errorf(arg.KeyPos, "duplicate key %s", arg.Key)
+		}
+		a.Args[arg.Key] = arg.Value
+		a.List = append(a.List, arg)
+		s.skipSpace()
+		switch s.peek() {
+		case ',':
+			s.pos++
+		case ')':
+		case 0:
+			return s.errorf(s.pos, "missing )")
+		default:
+			return s.errorf(s.pos, "expected , or ) after value of %s", arg.Key)
+		}
+	}
+}
+
+// quoted 解析双引号字符串，支持 \" \\ 转义
+func (s *scanner) quoted() (string, error) {
+	start := s.pos
+	s.pos++ // "
+	var sb strings.Builder
+	for s.pos < len(s.line) {
+		c := s.line[s.pos]
+		switch {
+		case c == '"':
+			s.pos++
+			return sb.String(), nil
+		case c == '\\' && s.pos+1 < len(s.line):
+			if next := s.line[s.pos+1]; next != '"' && next != '\\' {
+				return "", s.errorf(s.pos, "unknown escape \\%c, only \\\" and \\\\ are supported", next)
+			}
+			sb.WriteByte(s.line[s.pos+1])
+			s.pos += 2
+		default:
+			sb.WriteByte(c)
+			s.pos++
+		}
+	}
+	return "", s.errorf(start, "unterminated string")
+}
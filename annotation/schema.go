@@ -0,0 +1,87 @@
+package annotation
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Schema 一个注解可用的参数及说明，供编辑器补全、悬停提示和校验使用
+type Schema struct {
+	Name string // 带 @
+	Doc  string
+	Keys []Key
+}
+
+type Key struct {
+	Name     string
+	Doc      string
+	Values   []string // 可选值，为空时不限制
+	Required bool
+	Check    func(value string) error // 可选的值校验
+}
+
+var (
+	schemasMu sync.RWMutex
+	schemas   = map[string]Schema{}
+)
+
+// Register 注册注解的 Schema，同名时覆盖
+func Register(s Schema) {
+	schemasMu.Lock()
+	defer schemasMu.Unlock()
+	schemas[s.Name] = s
+}
+
+func Lookup(name string) (Schema, bool) {
+	schemasMu.RLock()
+	defer schemasMu.RUnlock()
+	s, ok := schemas[name]
+	return s, ok
+}
+
+// Schemas 按名字排序返回所有已注册的 Schema
+func Schemas() []Schema {
+	schemasMu.RLock()
+	defer schemasMu.RUnlock()
+	list := make([]Schema, 0, len(schemas))
+	for _, s := range schemas {
+		list = append(list, s)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Name < list[j].Name })
+	return list
+}
+
+func (s Schema) Key(name string) (Key, bool) {
+	for _, k := range s.Keys {
+		if k.Name == name {
+			return k, true
+		}
+	}
+	return Key{}, false
+}
+
+// Validate 检查注解的参数是否符合 Schema，返回的错误都是 *SyntaxError
+func (s Schema) Validate(a Annotation) []error {
+	var errs []error
+	for _, arg := range a.List {
+		k, ok := s.Key(arg.Key)
+		if !ok {
+			errs = append(errs, &SyntaxError{Offset: arg.KeyPos, Msg: fmt.Sprintf("unknown key %s for %s", arg.Key, s.Name)})
+		} else if len(k.Values) > 0 && !slices.Contains(k.Values, arg.Value) {
+			errs = append(errs, &SyntaxError{Offset: arg.ValuePos, Msg: fmt.Sprintf("invalid %s %q, expected one of %s", arg.Key, arg.Value, strings.Join(k.Values, ", "))})
+		} else if k.Check != nil {
+			if err := k.Check(arg.Value); err != nil {
+				errs = append(errs, &SyntaxError{Offset: arg.ValuePos, Msg: fmt.Sprintf("invalid %s: %s", arg.Key, err)})
+			}
+		}
+	}
+	for _, k := range s.Keys {
+		if _, ok := a.Args[k.Name]; k.Required && !ok {
+			errs = append(errs, &SyntaxError{Offset: a.Pos, Msg: fmt.Sprintf("%s requires %s", s.Name, k.Name)})
+		}
+	}
+	return errs
+}
@@ -0,0 +1,48 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/bwb0101/goAnnotations/generator"
+	"github.com/bwb0101/goAnnotations/logger"
+)
+
+// runClean 删除目录中所有由本工具生成的文件
+func runClean(args []string) int {
+	fs := newFlagSet("clean", "-dir <目录> [-r] [-n]")
+	cleanDir := fs.String("dir", "", "要清理的目录")
+	recursive := fs.Bool("r", false, "包括子目录")
+	dryRun := fs.Bool("n", false, "只列出要删除的文件")
+	_ = fs.Parse(args)
+	if *cleanDir == "" {
+		fs.Usage()
+		return exitError
+	}
+
+	dirs := []string{*cleanDir}
+	if *recursive {
+		var err error
+		if dirs, err = generator.OwnedDirs(*cleanDir); err != nil {
+			logger.Errorf("Error reading %s: %s", *cleanDir, err)
+			return exitError
+		}
+	}
+	owned, err := generator.FindOwned(dirs)
+	if err != nil {
+		logger.Errorf("Error looking for generated files: %s", err)
+		return exitError
+	}
+	for _, filename := range owned {
+		if *dryRun {
+			fmt.Println(filename)
+			continue
+		}
+		if err = os.Remove(filename); err != nil {
+			logger.Errorf("Error removing %s: %s", filename, err)
+			return exitError
+		}
+		logger.Infof("Removed %s", filename)
+	}
+	return exitOK
+}
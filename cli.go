@@ -0,0 +1,108 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"runtime"
+	"slices"
+
+	"github.com/bwb0101/goAnnotations/generator"
+	"github.com/bwb0101/goAnnotations/logger"
+)
+
+// 退出码，所有子命令一致(lsp 按 LSP 规范: 先 shutdown 再 exit 为 0，否则为 1)
+const (
+	exitOK      = 0
+	exitFailure = 1 // 检查未通过: check 有过时的文件、diff 有破坏性变更、golden 有差异
+	exitError   = 2 // 参数错误或运行出错
+)
+
+type command struct {
+	name    string
+	aliases []string
+	summary string
+	run     func(args []string) int
+}
+
+var commands []command
+
+func init() {
+	commands = []command{
+		{name: "generate", summary: "解析注解并生成代码(默认命令)", run: runGenerate},
+		{name: "check", aliases: []string{"verify"}, summary: "检查生成的文件是否最新，不写文件", run: runCheck},
+		{name: "parse", summary: "解析源码，输出 json，供 generate -from 使用", run: runParse},
+		{name: "schema", summary: "输出 parse/inspect json 的 JSON Schema", run: runSchema},
+		{name: "inspect", summary: "输出解析得到的结构(json/tree/table)", run: runInspect},
+		{name: "clean", summary: "删除生成的文件", run: runClean},
+		{name: "init", summary: "创建 goannotations.yaml", run: runInit},
+		{name: "diff", summary: "比较两个版本的源码，报告破坏性的 API 变更", run: runDiff},
+		{name: "golden", summary: "运行生成器的 golden 用例", run: runGolden},
+		{name: "lsp", summary: "注解的语言服务器(stdio)", run: runLsp},
+		{name: "version", summary: "输出版本", run: runVersion},
+		{name: "help", summary: "输出命令的帮助", run: runHelp},
+	}
+}
+
+func lookupCommand(name string) (command, bool) {
+	for _, c := range commands {
+		if c.name == name || slices.Contains(c.aliases, name) {
+			return c, true
+		}
+	}
+	return command{}, false
+}
+
+func printUsage() {
+	_, _ = fmt.Fprintf(os.Stderr, "\n用法:\n")
+	_, _ = fmt.Fprintf(os.Stderr, " %s <命令> [flags]\n\n命令:\n", os.Args[0])
+	for _, c := range commands {
+		_, _ = fmt.Fprintf(os.Stderr, "  %-10s %s\n", c.name, c.summary)
+	}
+	_, _ = fmt.Fprintf(os.Stderr, "\n%s help <命令> 查看命令的 flags；以 - 开头的参数视为 generate 的 flags\n", os.Args[0])
+	_, _ = fmt.Fprintf(os.Stderr, "退出码: 0 成功，1 检查未通过，2 参数错误或运行出错\n\n")
+}
+
+// newFlagSet 子命令的 FlagSet：统一的 -v/-q 和 -h 输出格式
+func newFlagSet(name, usage string) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ExitOnError)
+	fs.BoolFunc("v", "输出过程信息", func(string) error {
+		logger.SetLevel(logger.Verbose)
+		return nil
+	})
+	fs.BoolFunc("q", "只输出错误", func(string) error {
+		logger.SetLevel(logger.Quiet)
+		return nil
+	})
+	fs.Usage = func() {
+		_, _ = fmt.Fprintf(os.Stderr, "\n用法:\n")
+		_, _ = fmt.Fprintf(os.Stderr, " %s %s %s\n", os.Args[0], name, usage)
+		if c, ok := lookupCommand(name); ok {
+			_, _ = fmt.Fprintf(os.Stderr, " %s\n\n", c.summary)
+		}
+		fs.PrintDefaults()
+		_, _ = fmt.Fprintf(os.Stderr, "\n")
+	}
+	return fs
+}
+
+func runHelp(args []string) int {
+	if len(args) == 0 {
+		printUsage()
+		return exitOK
+	}
+	c, ok := lookupCommand(args[0])
+	if !ok || c.name == "help" {
+		logger.Errorf("unknown command %s", args[0])
+		printUsage()
+		return exitError
+	}
+	return c.run([]string{"-h"})
+}
+
+func runVersion(args []string) int {
+	fs := newFlagSet("version", "")
+	_ = fs.Parse(args)
+	fmt.Printf("goAnnotations %s %s %s/%s\n", generator.ToolVersion(), runtime.Version(), runtime.GOOS, runtime.GOARCH)
+	return exitOK
+}
@@ -0,0 +1,316 @@
+package compat
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/bwb0101/goAnnotations/generator/api"
+	"github.com/bwb0101/goAnnotations/model"
+)
+
+// Kind 破坏性变更的类型
+type Kind string
+
+const (
+	RouteRemoved         Kind = "route-removed"
+	RouteRenamed         Kind = "route-renamed"
+	MsgIdRemoved         Kind = "msgid-removed"
+	MsgIdChanged         Kind = "msgid-changed"
+	DataStructChanged    Kind = "datastruct-changed"
+	MethodRemoved        Kind = "method-removed"
+	PathParamTypeChanged Kind = "path-param-type-changed"
+	StructRemoved        Kind = "struct-removed"
+	FieldRemoved         Kind = "field-removed"
+	FieldTypeChanged     Kind = "field-type-changed"
+	EnumLiteralRemoved   Kind = "enum-literal-removed"
+)
+
+// Change 一条破坏性变更，Old/New 为变更前后的值(没有则为空)
+type Change struct {
+	Kind    Kind
+	Element string
+	Old     string
+	New     string
+}
+
+func (c Change) String() string {
+	switch {
+	case c.Old != "" && c.New != "":
+		return fmt.Sprintf("%s: %s: %s -> %s", c.Kind, c.Element, c.Old, c.New)
+	case c.Old != "":
+		return fmt.Sprintf("%s: %s: %s", c.Kind, c.Element, c.Old)
+	}
+	return fmt.Sprintf("%s: %s", c.Kind, c.Element)
+}
+
+// Compare 找出从 oldSources 到 newSources 中对客户端不兼容的变更，@Handler 不合法时返回错误；oldRoot/newRoot 为解析的根目录，
+// 元素按相对于根目录的目录和包名区分
+func Compare(oldRoot string, oldSources model.ParsedSources, newRoot string, newSources model.ParsedSources) ([]Change, error) {
+	oldHandlers, err := api.ExtractHandlers(oldSources.Operations)
+	if err != nil {
+		return nil, err
+	}
+	newHandlers, err := api.ExtractHandlers(newSources.Operations)
+	if err != nil {
+		return nil, err
+	}
+	var changes []Change
+	changes = append(changes, compareHandlers(oldRoot, oldHandlers, newRoot, newHandlers)...)
+	changes = append(changes, compareStructs(oldRoot, oldSources.Structs, oldHandlers, newRoot, newSources.Structs)...)
+	changes = append(changes, compareEnums(oldRoot, oldSources.Enums, newRoot, newSources.Enums)...)
+	sort.SliceStable(changes, func(i, j int) bool {
+		if changes[i].Kind != changes[j].Kind {
+			return changes[i].Kind < changes[j].Kind
+		}
+		return changes[i].Element < changes[j].Element
+	})
+	return changes, nil
+}
+
+// anyMethod 不限制 method 的 http 路由
+const anyMethod = "*"
+
+// route 客户端看到的接口标识: http 为 method 和 path，tcp/udp 为 msgId
+func route(h api.Handler) string {
+	if h.Net == "http" {
+		if h.Method == "" {
+			return h.Path
+		}
+		return strings.Join(h.Methods(), "|") + " " + h.Path
+	}
+	return h.MsgId
+}
+
+// routeKeys 一个 handler 注册的所有路由到 method: http 每个 method 一个，不限制 method 时为 *；tcp/udp 为 msgId，method 为空
+func routeKeys(h api.Handler) map[string]string {
+	if h.Net != "http" {
+		return map[string]string{h.Net + " " + h.MsgId: ""}
+	}
+	methods := h.Methods()
+	if len(methods) == 0 {
+		methods = []string{anyMethod}
+	}
+	keys := map[string]string{}
+	for _, m := range methods {
+		keys[h.Net+" "+m+" "+h.Path] = m
+	}
+	return keys
+}
+
+func compareHandlers(oldRoot string, oldHandlers []api.Handler, newRoot string, newHandlers []api.Handler) []Change {
+	var changes []Change
+	newByKey := map[string]api.Handler{}
+	newByRoute := map[string]api.Handler{}
+	newMethods := map[string][]string{} // http path -> 新的 method
+	for _, h := range newHandlers {
+		newByKey[h.Net+" "+h.KeyIn(newRoot)] = h
+		for k := range routeKeys(h) {
+			newByRoute[k] = h
+		}
+		if h.Net == "http" {
+			newMethods[h.Path] = append(newMethods[h.Path], h.Methods()...)
+		}
+	}
+	for _, oh := range oldHandlers {
+		if oh.Net == "http" && oh.Path == "" || oh.Net != "http" && oh.MsgId == "" {
+			continue
+		}
+		r, key := route(oh), oh.KeyIn(oldRoot)
+		compared := map[string]bool{}
+		missing := false
+		keys := routeKeys(oh)
+		for _, k := range sortedKeys(keys) {
+			nh, ok := newByRoute[k]
+			if !ok && oh.Net == "http" { // 新的不限制 method 时仍然兼容
+				nh, ok = newByRoute[oh.Net+" "+anyMethod+" "+oh.Path]
+			}
+			switch methods, found := newMethods[oh.Path]; {
+			case ok:
+				if !compared[nh.KeyIn(newRoot)] {
+					compared[nh.KeyIn(newRoot)] = true
+					changes = append(changes, compareHandler(oh, nh)...)
+				}
+			case found && oh.Net == "http":
+				changes = append(changes, Change{Kind: MethodRemoved, Element: oh.Net + " " + oh.Path, Old: keys[k], New: strings.Join(methods, "|")})
+			default:
+				missing = true
+			}
+		}
+		if !missing {
+			continue
+		}
+		nh, ok := newByKey[oh.Net+" "+key]
+		switch {
+		case ok && oh.Net == "http":
+			changes = append(changes, Change{Kind: RouteRenamed, Element: key, Old: r, New: route(nh)})
+		case ok:
+			changes = append(changes, Change{Kind: MsgIdChanged, Element: oh.Net + " " + key, Old: r, New: route(nh)})
+		case oh.Net == "http":
+			changes = append(changes, Change{Kind: RouteRemoved, Element: key, Old: r})
+		default:
+			changes = append(changes, Change{Kind: MsgIdRemoved, Element: oh.Net + " " + key, Old: r})
+		}
+	}
+	return changes
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// compareHandler 同一路由上的 handler: 请求体的 struct 和 path 参数的类型
+func compareHandler(oh, nh api.Handler) []Change {
+	var changes []Change
+	element := oh.Net + " " + route(oh)
+	if nh.DataPtrStruct != oh.DataPtrStruct {
+		changes = append(changes, Change{Kind: DataStructChanged, Element: element, Old: oh.DataPtrStruct, New: nh.DataPtrStruct})
+	}
+	newTypes := map[string]string{}
+	for _, p := range nh.PathParams() {
+		newTypes[p.Name] = p.Type
+	}
+	for _, p := range oh.PathParams() {
+		if t, ok := newTypes[p.Name]; ok && t != p.Type {
+			changes = append(changes, Change{Kind: PathParamTypeChanged, Element: element + " {" + p.Name + "}", Old: p.Type, New: t})
+		}
+	}
+	return changes
+}
+
+// relName 在 pkg.name 前加上 filename 所在目录相对于 root 的路径，同 api.Handler.KeyIn
+func relName(root, filename, name string) string {
+	rel, err := filepath.Rel(root, filepath.Dir(filename))
+	if err != nil || rel == "." {
+		return name
+	}
+	return filepath.ToSlash(rel) + "/" + name
+}
+
+// reachable handler 的 dataPtrStruct 以及它的字段引用到的 struct，只有这些会出现在客户端的请求中；
+// 嵌入的 struct 已经展开在外层的 struct 中比较，不单独返回
+func reachable(structs []model.Struct, handlers []api.Handler) []*model.Struct {
+	var found []*model.Struct
+	seen := map[*model.Struct]bool{}
+	var visit func(st *model.Struct, embedded bool)
+	visit = func(st *model.Struct, embedded bool) {
+		if st == nil || seen[st] {
+			return
+		}
+		seen[st] = true
+		if !embedded {
+			found = append(found, st)
+		}
+		for _, f := range st.Fields {
+			name, isEmbedded := f.JsonName()
+			if name == "" && !isEmbedded { // json:"-" 和未导出的字段
+				continue
+			}
+			visit(fieldStruct(structs, st, elementType(f.TypeName), f.PackageName), isEmbedded)
+		}
+	}
+	for _, h := range handlers {
+		if importPath, typeName, err := api.SplitDataPtrStruct(h.DataPtrStruct); err == nil {
+			pkg, name, _ := strings.Cut(strings.TrimPrefix(typeName, "*"), ".")
+			visit(model.FindStruct(structs, pkg, name, importPath), false)
+		}
+	}
+	return found
+}
+
+// fieldStruct st 的字段引用的 struct，typeName 为 pkg.T 时在 importPath 对应的包中查找，否则在 st 的包中
+func fieldStruct(structs []model.Struct, st *model.Struct, typeName, importPath string) *model.Struct {
+	if pkg, name, ok := strings.Cut(typeName, "."); ok {
+		return model.FindStruct(structs, pkg, name, importPath)
+	}
+	return model.FindStruct(structs, st.PackageName, typeName, filepath.Dir(st.Filename))
+}
+
+// elementType 去掉指针、slice 和 map 后的类型: *[]map[string]*pkg.T -> pkg.T
+func elementType(typeName string) string {
+	for {
+		f := model.Field{TypeName: strings.TrimPrefix(typeName, "*")}
+		switch {
+		case f.IsSlice():
+			typeName = f.SliceElementTypeName()
+		case f.IsMap():
+			_, typeName = f.SplitMapTypeNames()
+		default:
+			return f.TypeName
+		}
+	}
+}
+
+// jsonFields st 在 json 中的字段，嵌入的 struct 按 encoding/json 的规则展开，外层的字段优先
+func jsonFields(structs []model.Struct, st *model.Struct, fields map[string]model.Field, visited map[*model.Struct]bool) {
+	visited[st] = true
+	var embedded []*model.Struct
+	for _, f := range st.Fields {
+		name, isEmbedded := f.JsonName()
+		if isEmbedded {
+			if e := fieldStruct(structs, st, f.DereferencedTypeName(), f.PackageName); e != nil && !visited[e] {
+				embedded = append(embedded, e)
+			}
+			continue
+		}
+		if _, ok := fields[name]; name != "" && !ok {
+			fields[name] = f
+		}
+	}
+	for _, e := range embedded {
+		jsonFields(structs, e, fields, visited)
+	}
+}
+
+// compareStructs 比较旧的 handler 用到的 struct，字段按 json 中的名字对应
+func compareStructs(oldRoot string, oldStructs []model.Struct, oldHandlers []api.Handler, newRoot string, newStructs []model.Struct) []Change {
+	var changes []Change
+	newByName := map[string]*model.Struct{}
+	for i, s := range newStructs {
+		newByName[relName(newRoot, s.Filename, s.PackageName+"."+s.Name)] = &newStructs[i]
+	}
+	for _, st := range reachable(oldStructs, oldHandlers) {
+		name := relName(oldRoot, st.Filename, st.PackageName+"."+st.Name)
+		ns, ok := newByName[name]
+		if !ok {
+			changes = append(changes, Change{Kind: StructRemoved, Element: name})
+			continue
+		}
+		oldFields, newFields := map[string]model.Field{}, map[string]model.Field{}
+		jsonFields(oldStructs, st, oldFields, map[*model.Struct]bool{})
+		jsonFields(newStructs, ns, newFields, map[*model.Struct]bool{})
+		for key, of := range oldFields {
+			nf, ok := newFields[key]
+			if !ok {
+				changes = append(changes, Change{Kind: FieldRemoved, Element: name + "." + key, Old: of.TypeName})
+			} else if nf.TypeName != of.TypeName {
+				changes = append(changes, Change{Kind: FieldTypeChanged, Element: name + "." + key, Old: of.TypeName, New: nf.TypeName})
+			}
+		}
+	}
+	return changes
+}
+
+func compareEnums(oldRoot string, oldEnums []model.Enum, newRoot string, newEnums []model.Enum) []Change {
+	var changes []Change
+	newLiterals := map[string]bool{}
+	for _, e := range newEnums {
+		for _, l := range e.EnumLiterals {
+			newLiterals[relName(newRoot, e.Filename, e.PackageName+"."+e.Name+"."+l.Name)] = true
+		}
+	}
+	for _, e := range oldEnums {
+		for _, l := range e.EnumLiterals {
+			if name := relName(oldRoot, e.Filename, e.PackageName+"."+e.Name+"."+l.Name); !newLiterals[name] {
+				changes = append(changes, Change{Kind: EnumLiteralRemoved, Element: name, Old: l.Value})
+			}
+		}
+	}
+	return changes
+}
@@ -0,0 +1,130 @@
+package compat
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/bwb0101/goAnnotations/generator"
+	"github.com/bwb0101/goAnnotations/model"
+	"github.com/bwb0101/goAnnotations/parser"
+)
+
+const handlers = `package user
+
+// @Handler(type="api", net="http", path="/login", dataPtrStruct="example/user|user.LoginReq")
+func Login() {}
+`
+
+func parse(t *testing.T, files map[string]string) (string, model.ParsedSources) {
+	t.Helper()
+	root := t.TempDir()
+	for name, content := range files {
+		filename := filepath.Join(root, name)
+		if err := os.MkdirAll(filepath.Dir(filename), 0777); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filename, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	sources, err := parser.ParseSourceTree(root, "^.*.go$", generator.GenfileExcludePattern)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return root, sources
+}
+
+func TestCompare(t *testing.T) {
+	tests := []struct {
+		name     string
+		old, new map[string]string
+		want     []string
+	}{
+		{
+			name: "json rename",
+			old:  map[string]string{"user/user.go": handlers + "type LoginReq struct {\n\tName string `json:\"name\"`\n}\n"},
+			new:  map[string]string{"user/user.go": handlers + "type LoginReq struct {\n\tName string `json:\"username\"`\n}\n"},
+			want: []string{"field-removed: user/user.LoginReq.name: string"},
+		},
+		{
+			name: "go rename keeps json name",
+			old:  map[string]string{"user/user.go": handlers + "type LoginReq struct {\n\tName string `json:\"name\"`\n}\n"},
+			new:  map[string]string{"user/user.go": handlers + "type LoginReq struct {\n\tUserName string `json:\"name\"`\n}\n"},
+		},
+		{
+			name: "ignored and unexported fields",
+			old:  map[string]string{"user/user.go": handlers + "type LoginReq struct {\n\tName string\n\tCache string `json:\"-\"`\n\tsecret string\n}\n"},
+			new:  map[string]string{"user/user.go": handlers + "type LoginReq struct {\n\tName string\n}\n"},
+		},
+		{
+			name: "field type changed through embedded struct",
+			old:  map[string]string{"user/user.go": handlers + "type Base struct {\n\tId int\n}\n\ntype LoginReq struct {\n\tBase\n}\n"},
+			new:  map[string]string{"user/user.go": handlers + "type Base struct {\n\tId string\n}\n\ntype LoginReq struct {\n\tBase\n}\n"},
+			want: []string{"field-type-changed: user/user.LoginReq.Id: int -> string"},
+		},
+		{
+			name: "unreachable struct removed",
+			old:  map[string]string{"user/user.go": handlers + "type LoginReq struct{}\n\ntype cache struct {\n\tName string\n}\n"},
+			new:  map[string]string{"user/user.go": handlers + "type LoginReq struct{}\n"},
+		},
+		{
+			name: "method removed",
+			old: map[string]string{"user/user.go": `package user
+
+// @Handler(type="api", net="http", method="GET|POST", path="/user")
+func User() {}
+`},
+			new: map[string]string{"user/user.go": `package user
+
+// @Handler(type="api", net="http", method="GET", path="/user")
+func User() {}
+`},
+			want: []string{"method-removed: http /user: POST -> GET"},
+		},
+		{
+			name: "path param type changed",
+			old: map[string]string{"user/user.go": `package user
+
+// @Handler(type="api", net="http", method="GET", path="/user/{id}", params="id:int64")
+func User() {}
+`},
+			new: map[string]string{"user/user.go": `package user
+
+// @Handler(type="api", net="http", method="GET", path="/user/{id}", params="id:string")
+func User() {}
+`},
+			want: []string{"path-param-type-changed: http GET /user/{id} {id}: int64 -> string"},
+		},
+		{
+			name: "same package name in different dirs",
+			old: map[string]string{
+				"a/user/user.go": "package user\n\n// @Handler(type=\"api\", net=\"tcp\", msgId=\"1\")\nfunc Login() {}\n",
+				"b/user/user.go": "package user\n\n// @Handler(type=\"api\", net=\"tcp\", msgId=\"2\")\nfunc Login() {}\n",
+			},
+			new: map[string]string{
+				"a/user/user.go": "package user\n\n// @Handler(type=\"api\", net=\"tcp\", msgId=\"1\")\nfunc Login() {}\n",
+				"b/user/user.go": "package user\n\n// @Handler(type=\"api\", net=\"tcp\", msgId=\"3\")\nfunc Login() {}\n",
+			},
+			want: []string{"msgid-changed: tcp b/user/user.Login: 2 -> 3"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			oldRoot, oldSources := parse(t, tt.old)
+			newRoot, newSources := parse(t, tt.new)
+			changes, err := Compare(oldRoot, oldSources, newRoot, newSources)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var got []string
+			for _, c := range changes {
+				got = append(got, c.String())
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("Compare =\n%q\nwant\n%q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,181 @@
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Filenames 按顺序查找的配置文件名
+var Filenames = []string{"goannotations.yaml", "goannotations.yml", "goannotations.json"}
+
+// Config 项目配置，从输入目录开始逐级向上查找 goannotations.yaml(或 .json)。
+// 配置中的相对路径都相对于配置文件所在的目录
+type Config struct {
+	Generators   map[string]Generator `json:"generators,omitempty" yaml:"generators,omitempty"`
+	Plugins      map[string]Plugin    `json:"plugins,omitempty" yaml:"plugins,omitempty"`
+	TemplatesDir string               `json:"templatesDir,omitempty" yaml:"templatesDir,omitempty"` // 自定义模板目录，默认 <输入目录>/.goannotations/templates
+	Api          Api                  `json:"api" yaml:"api"`
+	Model        Model                `json:"model" yaml:"model"`
+	OpenApi      OpenApi              `json:"openapi,omitempty" yaml:"openapi,omitempty"`
+
+	filename string // 读取的配置文件，没有时为空
+}
+
+type Generator struct {
+	Enabled *bool             `json:"enabled,omitempty" yaml:"enabled,omitempty"` // 不写时默认启用(openapi 除外)
+	Options map[string]string `json:"options,omitempty" yaml:"options,omitempty"` // 生成器自定义的选项，插件会原样收到
+}
+
+// Plugin 进程外生成器，Path 为空时在 PATH 中查找 goannotations-gen-<name>
+type Plugin struct {
+	Path    string            `json:"path,omitempty" yaml:"path,omitempty"`
+	Options map[string]string `json:"options,omitempty" yaml:"options,omitempty"`
+}
+
+// Api api 生成器引用的框架包和 HTTP 路由注册方式
+type Api struct {
+	NetFwImport     string                `json:"netFwImport,omitempty" yaml:"netFwImport,omitempty"`
+	MultipartImport string                `json:"multipartImport,omitempty" yaml:"multipartImport,omitempty"` // valid.file 使用
+	HttpBackend     string                `json:"httpBackend,omitempty" yaml:"httpBackend,omitempty"`         // netfw/nethttp/fasthttp，默认 netfw
+	Packages        map[string]ApiPackage `json:"packages,omitempty" yaml:"packages,omitempty"`               // 按包名覆盖
+	Funcs           string                `json:"funcs,omitempty" yaml:"funcs,omitempty"`                     // 处理哪些函数上的 @Handler: all/static/method，默认 all
+}
+
+type ApiPackage struct {
+	HttpBackend string `json:"httpBackend,omitempty" yaml:"httpBackend,omitempty"`
+	MsgIdRange  string `json:"msgIdRange,omitempty" yaml:"msgIdRange,omitempty"` // tcp/udp msgId 的范围，例如 1000-1999，不写不限制
+}
+
+// Model model 生成器的包名、引用的框架包和输出文件
+type Model struct {
+	Package            string `json:"package,omitempty" yaml:"package,omitempty"`
+	FrameworkLibImport string `json:"frameworkLibImport,omitempty" yaml:"frameworkLibImport,omitempty"`
+	StorageImport      string `json:"storageImport,omitempty" yaml:"storageImport,omitempty"`
+	Output             string `json:"output,omitempty" yaml:"output,omitempty"` // 默认 <输入目录>/columns.go
+}
+
+// OpenApi openapi 生成器的输出，默认不运行，需要在 generators 中打开
+type OpenApi struct {
+	Output      string `json:"output,omitempty" yaml:"output,omitempty"`           // 默认 <输入目录>/openapi.json，.yaml/.yml 输出 YAML
+	Title       string `json:"title,omitempty" yaml:"title,omitempty"`             // 默认 API
+	Version     string `json:"version,omitempty" yaml:"version,omitempty"`         // 默认 1.0.0
+	TokenHeader string `json:"tokenHeader,omitempty" yaml:"tokenHeader,omitempty"` // validation="token" 时 token 所在的 header，默认 token
+	Recursive   bool   `json:"recursive,omitempty" yaml:"recursive,omitempty"`     // 在输入目录的子目录中也查找 dataPtrStruct 引用的 struct
+}
+
+// Default 没有配置文件时使用的默认值
+func Default() Config {
+	return Config{
+		Api: Api{
+			NetFwImport:     "framework/common/net_fw",
+			MultipartImport: "github.com/valyala/fasthttp/zzz/mime/multipart",
+		},
+		Model: Model{
+			Package:            "model_user",
+			FrameworkLibImport: "common/framework_lib",
+			StorageImport:      "common/framework_lib/storage",
+		},
+	}
+}
+
+// Filename 读取的配置文件，没有找到配置文件时为空
+func (c Config) Filename() string {
+	return c.filename
+}
+
+// Resolve 把配置中的相对路径转成相对于配置文件目录的路径
+func (c Config) Resolve(path string) string {
+	if path == "" || filepath.IsAbs(path) || c.filename == "" {
+		return path
+	}
+	return filepath.Join(filepath.Dir(c.filename), path)
+}
+
+// Enabled 返回配置中明确打开(true)或关闭(false)的生成器
+func (c Config) Enabled() map[string]bool {
+	enabled := map[string]bool{}
+	for name, g := range c.Generators {
+		if g.Enabled != nil {
+			enabled[name] = *g.Enabled
+		}
+	}
+	return enabled
+}
+
+// Options 返回生成器 name 的选项
+func (c Config) Options(name string) map[string]string {
+	return c.Generators[name].Options
+}
+
+// Find 从 dir 开始逐级向上查找配置文件，没有找到时返回空串
+func Find(dir string) (string, error) {
+	return FindWithin(dir, "")
+}
+
+// FindWithin 同 Find，但只查到 root 为止，root 为空时查到根目录
+func FindWithin(dir, root string) (string, error) {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return "", err
+	}
+	if root != "" {
+		if root, err = filepath.Abs(root); err != nil {
+			return "", err
+		}
+	}
+	for {
+		for _, name := range Filenames {
+			filename := filepath.Join(dir, name)
+			if fi, err := os.Stat(filename); err == nil && !fi.IsDir() {
+				return filename, nil
+			} else if err != nil && !errors.Is(err, fs.ErrNotExist) {
+				return "", err
+			}
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir || dir == root {
+			return "", nil
+		}
+		dir = parent
+	}
+}
+
+// Load 查找并读取 dir 适用的配置文件，没有配置文件时返回默认配置
+func Load(dir string) (Config, error) {
+	return LoadWithin(dir, "")
+}
+
+// LoadWithin 同 Load，但只查到 root 为止，例如 golden 用例不受上级目录中配置的影响
+func LoadWithin(dir, root string) (Config, error) {
+	filename, err := FindWithin(dir, root)
+	if err != nil || filename == "" {
+		return Default(), err
+	}
+	return LoadFile(filename)
+}
+
+// LoadFile 读取配置文件，未设置的项使用默认值
+func LoadFile(filename string) (Config, error) {
+	cfg := Default()
+	b, err := os.ReadFile(filename)
+	if err != nil {
+		return cfg, err
+	}
+	if strings.HasSuffix(filename, ".json") {
+		err = json.Unmarshal(b, &cfg)
+	} else {
+		err = yaml.Unmarshal(b, &cfg)
+	}
+	if err != nil {
+		return cfg, fmt.Errorf("Error decoding %s: %s", filename, err)
+	}
+	cfg.filename = filename
+	return cfg, nil
+}
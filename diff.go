@@ -0,0 +1,50 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/bwb0101/goAnnotations/compat"
+	"github.com/bwb0101/goAnnotations/generator"
+	"github.com/bwb0101/goAnnotations/logger"
+	"github.com/bwb0101/goAnnotations/parser"
+)
+
+// runDiff 比较两个源码目录，存在破坏性变更时返回 exitFailure
+func runDiff(args []string) int {
+	fs := newFlagSet("diff", "<旧目录> <新目录>")
+	_ = fs.Parse(args)
+	if fs.NArg() != 2 {
+		fs.Usage()
+		return exitError
+	}
+
+	oldSources, err := parser.ParseSourceTree(fs.Arg(0), "^.*.go$", generator.GenfileExcludePattern)
+	if err != nil {
+		logger.Errorf("Error parsing %s: %s", fs.Arg(0), err)
+		return exitError
+	}
+	newSources, err := parser.ParseSourceTree(fs.Arg(1), "^.*.go$", generator.GenfileExcludePattern)
+	if err != nil {
+		logger.Errorf("Error parsing %s: %s", fs.Arg(1), err)
+		return exitError
+	}
+
+	changes, err := compat.Compare(fs.Arg(0), oldSources, fs.Arg(1), newSources)
+	if err != nil {
+		var errs generator.ErrorList
+		errs.Add(err)
+		errs.Sort()
+		for _, e := range errs {
+			logger.Errorf("%s", e)
+		}
+		return exitError
+	}
+	for _, c := range changes {
+		fmt.Println(c)
+	}
+	if len(changes) > 0 {
+		logger.Infof("%d breaking change(s)", len(changes))
+		return exitFailure
+	}
+	return exitOK
+}
@@ -0,0 +1,284 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/bwb0101/goAnnotations/config"
+	"github.com/bwb0101/goAnnotations/generator"
+	"github.com/bwb0101/goAnnotations/generator/api"
+	"github.com/bwb0101/goAnnotations/generator/plugin"
+	"github.com/bwb0101/goAnnotations/logger"
+	"github.com/bwb0101/goAnnotations/model"
+	"github.com/bwb0101/goAnnotations/parser"
+)
+
+type generateOptions struct {
+	dir        string
+	generators string
+	pkgName    string
+	file       string
+	funcs      string
+	from       string
+	check      bool
+}
+
+func runGenerate(args []string) int {
+	return generate(args, "generate", false)
+}
+
+// runCheck 同 generate -check
+func runCheck(args []string) int {
+	return generate(args, "check", true)
+}
+
+func generate(args []string, name string, check bool) int {
+	var o generateOptions
+	var legacyModel string
+	fs := newFlagSet(name, "[-dir <目录>] [-from <model.json>] [-generators a,b] [-pkg <包名>] [-file <文件>]")
+	fs.StringVar(&o.dir, "dir", "", "要检查的目录，go generate 时默认为当前目录")
+	fs.StringVar(&o.dir, "input-dir", "", "同 -dir")
+	fs.StringVar(&legacyModel, "model", "", "同 -generators (已废弃)")
+	fs.StringVar(&o.generators, "generators", "", "要运行的生成器，逗号分隔，空为全部: "+strings.Join(registry.Names(), ","))
+	fs.StringVar(&o.pkgName, "pkg", "", "包名，go generate 时默认为 $GOPACKAGE")
+	fs.StringVar(&o.funcs, "funcs", "", "处理哪些函数上的 @Handler: all(默认)/static(非struct的函数)/method(struct的方法)，覆盖配置中的 api.funcs")
+	staticFunc := fs.Bool("static_func", false, "同 -funcs static (已废弃)")
+	fs.StringVar(&o.from, "from", "", "不解析源码，读取 parse 命令输出的 json(- 为 stdin)；文件名相对于运行 parse 时的工作目录")
+	fs.StringVar(&o.file, "file", "", "只写入由该文件生成的文件(仍按整个包生成)，例如 //go:generate goAnnotations -file $GOFILE")
+	if !check {
+		fs.BoolVar(&o.check, "check", false, "同 check 命令")
+	}
+	_ = fs.Parse(args)
+	o.check = o.check || check
+	if *staticFunc && o.funcs == "" {
+		o.funcs = string(api.FuncsStatic)
+	}
+	if o.generators == "" {
+		o.generators = legacyModel
+	}
+
+	// go generate 会设置 GOFILE/GOLINE/GOPACKAGE，工作目录为指令所在文件的目录
+	if goFile := os.Getenv("GOFILE"); goFile != "" {
+		log.SetPrefix(fmt.Sprintf("%s:%s: ", goFile, os.Getenv("GOLINE")))
+		if o.dir == "" {
+			o.dir = "."
+		}
+		if o.pkgName == "" {
+			o.pkgName = os.Getenv("GOPACKAGE")
+		}
+	}
+	if o.dir == "" && o.from != "" {
+		o.dir = "." // 只用来查找配置
+	}
+	if o.dir == "" {
+		logger.Errorf("-dir is required")
+		fs.Usage()
+		return exitError
+	}
+
+	cfg, err := config.Load(o.dir)
+	if err != nil {
+		logger.Errorf("Error loading config: %s", err)
+		return exitError
+	}
+	var parsedSources model.ParsedSources
+	source := o.dir
+	if o.from != "" {
+		source = o.from
+		parsedSources, err = model.Parse(o.from)
+	} else {
+		parsedSources, err = parser.ParseSourceDir(o.dir, "^.*.go$", generator.GenfileExcludePattern)
+	}
+	if err != nil {
+		logger.Errorf("Error parsing %s: %s", source, err)
+		return exitError
+	}
+	return runAllGenerators(o, cfg, parsedSources)
+}
+
+func runAllGenerators(o generateOptions, cfg config.Config, parsedSources model.ParsedSources) int {
+	inputDir := o.dir
+	parsedSources.PkgName = o.pkgName
+	var err error
+	if o.funcs != "" {
+		cfg.Api.Funcs = o.funcs
+	}
+	if cfg.Filename() != "" {
+		logger.Debugf("Using config %s", cfg.Filename())
+	}
+	selected := splitList(o.generators)
+	if err = registerPlugins(cfg, selected); err != nil {
+		logger.Errorf("Error registering plugins: %s", err)
+		return exitError
+	}
+	entries, err := registry.Resolve(selected, cfg.Enabled())
+	if err != nil {
+		logger.Errorf("Error selecting generators: %s", err)
+		return exitError
+	}
+	writer := generator.NewWriter()
+	// 运行所有的生成器，错误汇总后一起报告，有错误时不写入任何文件
+	var errs generator.ErrorList
+	failed := 0
+	for _, e := range entries {
+		logger.Debugf("Running generator %s", e.Name)
+		files, err := e.New(cfg).Generate(inputDir, parsedSources)
+		if o.file != "" { // 按整个包生成，只写入由该文件生成的文件
+			files = generator.WithSource(files, o.file)
+		}
+		if err == nil {
+			err = writer.Add(e.Name, files)
+		}
+		if err != nil {
+			n := len(errs)
+			errs.Add(err)
+			for _, ge := range errs[n:] {
+				if ge.Generator == "" {
+					ge.Generator = e.Name
+				}
+			}
+			failed++
+		}
+	}
+	if len(errs) > 0 {
+		errs.Sort()
+		for _, e := range errs {
+			logger.Errorf("%s", e)
+		}
+		logger.Errorf("%d error(s) in %d generator(s)", len(errs), failed)
+		return exitError
+	}
+	var stale []string
+	// 只处理一个文件时无法判断其他生成的文件是否过时；只清理这次运行了的生成器的文件
+	if o.file == "" {
+		staleRoot := inputDir
+		if o.from != "" { // -dir 只用来查找配置，只清理 json 中的源文件所在的目录
+			staleRoot = ""
+		}
+		if stale, err = writer.Stale(sourceDirs(staleRoot, parsedSources)); err != nil {
+			logger.Errorf("Error looking for stale generated files: %s", err)
+			return exitError
+		}
+	}
+	if o.check {
+		diffs, err := writer.Diff()
+		if err != nil {
+			logger.Errorf("Error checking generated files: %s", err)
+			return exitError
+		}
+		for _, d := range diffs {
+			fmt.Print(d)
+		}
+		for _, filename := range stale {
+			fmt.Printf("stale generated file: %s\n", filename)
+		}
+		if len(diffs)+len(stale) > 0 {
+			logger.Errorf("%d generated file(s) out of date, rerun %s generate", len(diffs)+len(stale), os.Args[0])
+			return exitFailure
+		}
+		logger.Infof("Generated files are up to date")
+		return exitOK
+	}
+	stats, err := writer.Write()
+	if err != nil {
+		logger.Errorf("Error writing generated files: %s", err)
+		return exitError
+	}
+	for _, filename := range stale {
+		if err = os.Remove(filename); err != nil {
+			logger.Errorf("Error removing stale generated file: %s", err)
+			return exitError
+		}
+		logger.Debugf("Removed stale generated file %s", filename)
+	}
+	logger.Infof("Generated files: %s, %d removed", stats, len(stale))
+	return exitOK
+}
+
+// sourceDirs 输入目录(不为空时)以及所有解析到的源文件所在的目录，生成的文件只会出现在这些目录中
+func sourceDirs(inputDir string, parsedSources model.ParsedSources) []string {
+	var dirs []string
+	if inputDir != "" {
+		dirs = append(dirs, inputDir)
+	}
+	add := func(filename string) {
+		if dir := filepath.Dir(filename); !slices.Contains(dirs, dir) {
+			dirs = append(dirs, dir)
+		}
+	}
+	for _, s := range parsedSources.Structs {
+		add(s.Filename)
+	}
+	for _, o := range parsedSources.Operations {
+		add(o.Filename)
+	}
+	for _, i := range parsedSources.Interfaces {
+		add(i.Filename)
+	}
+	for _, t := range parsedSources.Typedefs {
+		add(t.Filename)
+	}
+	for _, e := range parsedSources.Enums {
+		add(e.Filename)
+	}
+	return dirs
+}
+
+// registerPlugins 注册配置中的插件，以及 -generators 中未注册、但 PATH 中存在的 goannotations-gen-<name>
+func registerPlugins(cfg config.Config, selected []string) error {
+	names := make([]string, 0, len(cfg.Plugins))
+	for name := range cfg.Plugins {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		e, err := pluginEntry(cfg, name)
+		if err == nil {
+			err = registry.Register(e)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	for _, name := range selected {
+		if slices.Contains(registry.Names(), name) {
+			continue
+		}
+		if e, err := pluginEntry(cfg, name); err == nil {
+			if err = registry.Register(e); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// pluginEntry 配置中的插件 name，配置中没有时在 PATH 中查找 goannotations-gen-<name>
+func pluginEntry(cfg config.Config, name string) (generator.Entry, error) {
+	p, ok := cfg.Plugins[name]
+	if p.Path == "" {
+		path, err := plugin.Lookup(name)
+		if err != nil {
+			return generator.Entry{}, err
+		}
+		p.Path = path
+	} else if strings.ContainsRune(p.Path, filepath.Separator) { // 只有文件名时在 PATH 中查找
+		p.Path = cfg.Resolve(p.Path)
+	}
+	return generator.Entry{Name: name, New: func(cfg config.Config) generator.Generator {
+		options := maps.Clone(cfg.Options(name))
+		if options == nil {
+			options = map[string]string{}
+		}
+		if ok {
+			maps.Copy(options, p.Options)
+		}
+		return plugin.NewGeneratorPlugin(name, p.Path, options)
+	}}, nil
+}
@@ -10,287 +10,308 @@ package api
 import (
 	"fmt"
 	"path/filepath"
+	"slices"
+	"strconv"
 	"strings"
 	"text/template"
 
+	"github.com/bwb0101/goAnnotations/annotation"
 	"github.com/bwb0101/goAnnotations/generator"
 	"github.com/bwb0101/goAnnotations/generator/util"
 	"github.com/bwb0101/goAnnotations/model"
 )
 
 type templateData struct {
-	PackageName string
-	TargetDir   string
-	// Services    []model.Operation
+	PackageName   string
+	TargetDir     string
+	options       Options
+	httpBackend   httpBackend
 	httpImports   map[string]string
 	httpCodes     map[string]map[string]string
 	httpCodesList []string
+	httpSources   []string
 	//
 	tcpImports   map[string]string
 	tcpCodes     map[string]map[string]string
 	tcpCodesList []string
+	tcpSources   []string
 	//
 	udpImports   map[string]string
 	udpCodes     map[string]map[string]string
 	udpCodesList []string
+	udpSources   []string
+	//
+	services       map[string]string // receiver 变量 -> 创建的表达式，生成到 gen_api_services.go
+	serviceSources []string
+	//
+	positions map[string]generator.Position // key -> 第一个 @Handler 所在的位置
+}
+
+// Options api 生成器引用的框架包，生成的代码中分别以 net_fw、multipart 作为包名引用
+type Options struct {
+	NetFwImport     string
+	MultipartImport string
+	// HttpBackend net=http 的路由注册方式，见 HttpBackends，默认 netfw
+	HttpBackend string
+	// PackageHttpBackends 按包名单独指定 HttpBackend
+	PackageHttpBackends map[string]string
+	// PackageMsgIdRanges 按包名限制 tcp/udp msgId 的范围，例如 1000-1999
+	PackageMsgIdRanges map[string]string
+	// Funcs 处理哪些函数上的 @Handler: 非 struct 的函数、struct 的方法或全部，默认全部
+	Funcs FuncMode
 }
 
 type GeneratorApi struct {
 	targetFilename string
+	options        Options
 }
 
-func NewGeneratorApi() generator.Generator {
-	return &GeneratorApi{}
+func NewGeneratorApi(options Options) generator.Generator {
+	return &GeneratorApi{options: options}
 }
 
-func (eg *GeneratorApi) Generate(inputDir string, parsedSources model.ParsedSources) error {
-	pkgName := ""
+func (eg *GeneratorApi) Generate(inputDir string, parsedSources model.ParsedSources) ([]generator.File, error) {
+	if err := eg.options.Funcs.check(); err != nil {
+		return nil, err
+	}
 	var datas = map[string]*templateData{}
-	var data *templateData
+	var errs generator.ErrorList
+	services := findServices(parsedSources.Structs)
+	routes, err := newRouteTable(eg.options.PackageMsgIdRanges)
+	if err != nil {
+		return nil, err
+	}
 	//
 	for _, operation := range parsedSources.Operations {
-		if operation.PackageName != pkgName { // 同package合成一个文件
+		if !eg.options.Funcs.includes(operation) || !isHandler(operation) {
+			continue
+		}
+		// 同目录同package合成一个文件，写到源文件所在的目录
+		targetDir := inputDir
+		if operation.Filename != "" {
+			targetDir = filepath.Dir(operation.Filename)
+		}
+		handlers, err := parseHandlers(operation)
+		if err != nil {
+			errs.Add(err)
+			continue
+		}
+		if err = routes.add(operation, handlers); err != nil {
+			errs.Add(err)
+			continue
+		}
+		apiExpr, svc, err := handlerFunc(operation, targetDir, services)
+		if err != nil {
+			errs.Add(generator.Errorf(handlerPos(operation), "%s", err))
+			continue
+		}
+		data := datas[targetDir+"|"+operation.PackageName]
+		if data == nil {
+			netFw := fmt.Sprintf(`net_fw "%s"`, eg.options.NetFwImport)
+			backend, err := eg.options.httpBackendFor(operation.PackageName)
+			if err != nil { // 只影响 net=http，tcp/udp 照常生成
+				errs.Add(generator.Errorf(handlerPos(operation), "%s", err))
+			}
 			data = &templateData{
-				httpImports: map[string]string{`"framework/common/net_fw"`: `"framework/common/net_fw"`}, httpCodes: make(map[string]map[string]string),
-				tcpImports: map[string]string{`"framework/common/net_fw"`: `"framework/common/net_fw"`}, tcpCodes: make(map[string]map[string]string),
-				udpImports: map[string]string{`"framework/common/net_fw"`: `"framework/common/net_fw"`}, udpCodes: make(map[string]map[string]string),
+				PackageName: operation.PackageName,
+				TargetDir:   targetDir,
+				options:     eg.options,
+				httpBackend: backend,
+				httpImports: map[string]string{}, httpCodes: make(map[string]map[string]string),
+				tcpImports: map[string]string{netFw: netFw}, tcpCodes: make(map[string]map[string]string),
+				udpImports: map[string]string{netFw: netFw}, udpCodes: make(map[string]map[string]string),
+				services: map[string]string{}, positions: map[string]generator.Position{},
 			}
-			pkgName = operation.PackageName
-			// if targetDir, err := util.DetermineTargetPath(inputDir, pkgName); err != nil {
-			// 	return err
-			// } else {
-			datas[pkgName] = data
-			data.PackageName = pkgName
-			// data.TargetDir = targetDir
-			// }
+			datas[targetDir+"|"+operation.PackageName] = data
 		}
-		parseAnnotation(operation, data)
+		parseAnnotation(operation, data, apiExpr, handlers)
+		data.addService(svc)
 	}
-	if err := generate_http(datas, inputDir); err == nil {
-		if err := generate_tcp(datas, inputDir); err == nil {
-			return generate_udp(datas, inputDir)
-		}
+	var files []generator.File
+	for _, gen := range []func(map[string]*templateData) ([]generator.File, error){generate_http, generate_tcp, generate_udp, generate_services} {
+		fs, err := gen(datas)
+		errs.Add(err)
+		files = append(files, fs...)
 	}
-	return nil
+	return files, errs.Err()
 }
 
-func generate_http(datas map[string]*templateData, dir string) error {
+func generate_http(datas map[string]*templateData) ([]generator.File, error) {
+	var files []generator.File
+	var errs generator.ErrorList
 	for _, data := range datas {
-		if len(data.httpCodes) > 0 {
-			if err := util.Generate(util.Info{
+		if len(data.httpCodes) > 0 && data.httpBackend != nil {
+			if err := data.checkHttpCodes(); err != nil {
+				errs.Add(err)
+				continue
+			}
+			f, err := util.Generate(util.Info{
 				Data:           *data,
-				Src:            data.PackageName,
-				TargetFilename: filepath.Join(dir, generator.GenfilePrefix+"http_api_handler.go"),
+				Sources:        data.httpSources,
+				TargetFilename: filepath.Join(data.TargetDir, generator.GenfilePrefix+"http_api_handler.go"),
 				TemplateName:   "api_http",
-				TemplateString: httpHandlersTemplate,
+				TemplateString: data.httpBackend.template(),
 				FuncMap:        customHttpTemplateFuncs,
-			}); err != nil {
-				return err
+			})
+			if err != nil {
+				errs.Add(err)
+				continue
 			}
+			files = append(files, f)
 		}
 	}
-	return nil
+	return files, errs.Err()
 }
 
-func generate_tcp(datas map[string]*templateData, dir string) error {
+func generate_tcp(datas map[string]*templateData) ([]generator.File, error) {
+	var files []generator.File
+	var errs generator.ErrorList
 	for _, data := range datas {
 		if len(data.tcpCodes) > 0 {
-			if err := util.Generate(util.Info{
+			f, err := util.Generate(util.Info{
 				Data:           *data,
-				Src:            data.PackageName,
-				TargetFilename: filepath.Join(dir, generator.GenfilePrefix+"tcp_api_handler.go"),
+				Sources:        data.tcpSources,
+				TargetFilename: filepath.Join(data.TargetDir, generator.GenfilePrefix+"tcp_api_handler.go"),
 				TemplateName:   "api_tcp",
 				TemplateString: tcpHandlersTemplate,
 				FuncMap:        customTcpTemplateFuncs,
-			}); err != nil {
-				return err
+			})
+			if err != nil {
+				errs.Add(err)
+				continue
 			}
+			files = append(files, f)
 		}
 	}
-	return nil
+	return files, errs.Err()
 }
 
-func generate_udp(datas map[string]*templateData, dir string) error {
+func generate_udp(datas map[string]*templateData) ([]generator.File, error) {
+	var files []generator.File
+	var errs generator.ErrorList
 	for _, data := range datas {
 		if len(data.udpCodes) > 0 {
-			if err := util.Generate(util.Info{
+			f, err := util.Generate(util.Info{
 				Data:           *data,
-				Src:            data.PackageName,
-				TargetFilename: filepath.Join(dir, generator.GenfilePrefix+"udp_api_handler.go"),
+				Sources:        data.udpSources,
+				TargetFilename: filepath.Join(data.TargetDir, generator.GenfilePrefix+"udp_api_handler.go"),
 				TemplateName:   "api_udp",
 				TemplateString: udpHandlersTemplate,
 				FuncMap:        customUdpTemplateFuncs,
-			}); err != nil {
-				return err
+			})
+			if err != nil {
+				errs.Add(err)
+				continue
 			}
+			files = append(files, f)
 		}
 	}
-	return nil
+	return files, errs.Err()
 }
 
-func parseAnnotation(op model.Operation, data *templateData) {
-	for _, line := range op.DocLines {
-		line = strings.TrimSpace(line[2:])
-		if strings.HasPrefix(line, "@Handler") { // @Handler(type="...")
-			line = strings.TrimSpace(line[len("@Handler"):])
-			line = line[1 : len(line)-1] // 去掉括号
-			lines := strings.Split(line, ",")
-			if strings.Contains(lines[0], "api") { // type="api"
-				parseHandlerApi(lines[1:], data, op.Filename+op.Name, op.Name)
-			} else if strings.Contains(lines[0], "valid.limit") {
-				parseHandlerValid_limit(lines[1:], data, op.Filename+op.Name)
-			} else if strings.Contains(lines[0], "valid.file") {
-				parseHandlerValid_file(lines[1:], data, op.Filename+op.Name)
-			}
+// parseAnnotation apiExpr 为注册时引用的 handler，方法为 svcXxx.Method；handlers 为 parseHandlers 的结果
+func parseAnnotation(op model.Operation, data *templateData, apiExpr string, handlers []annotation.Annotation) {
+	key := operationKey(op)
+	data.positions[key] = handlerPos(op)
+	for _, a := range handlers {
+		switch a.Args["type"] {
+		case "api":
+			parseHandlerApi(a.Args, data, key, apiExpr, op.Name)
+		case "valid.limit":
+			parseHandlerValid_limit(a.Args, data, key)
+		case "valid.file":
+			parseHandlerValid_file(a.Args, data, key)
 		}
 	}
+	data.addSource(op.Filename, key)
 }
 
-// @Handler(type="api", net = "http/tcp", path = "/reg", bodyLimit = n, resp = "object", validation = "token")
-func parseHandlerApi(words []string, data *templateData, key, apiName string) {
-	net := ""
-	for _, ll := range words {
-		kv := strings.Split(ll, "=")
-		switch k := strings.TrimSpace(kv[0]); k {
-		case "net":
-			if net = strings.TrimSpace(kv[1]); net == `"http"` {
-				if data.httpCodes[key] == nil {
-					data.httpCodes[key] = map[string]string{"api": apiName, "api_method": apiName}
-					data.httpCodesList = append(data.httpCodesList, key)
-				}
-				//
-				data.httpCodes[key][k] = "net_fw.HTNET_type_http"
-			} else if net == `"tcp"` {
-				if data.tcpCodes[key] == nil {
-					data.tcpCodes[key] = map[string]string{"api": apiName, "api_method": apiName}
-					data.tcpCodesList = append(data.tcpCodesList, key)
-				}
-			} else if net == `"udp"` {
-				if data.udpCodes[key] == nil {
-					data.udpCodes[key] = map[string]string{"api": apiName, "api_method": apiName}
-					data.udpCodesList = append(data.udpCodesList, key)
-				}
-			}
-		case "path":
-			data.httpCodes[key][k] = strings.TrimSpace(kv[1])
-		case "msgId":
-			if v := strings.TrimSpace(kv[1]); v != "" {
-				v = v[1 : len(v)-1] // 去掉两边引号
-				if v != "" {
-					if net == `"tcp"` {
-						data.tcpCodes[key][k] = v
-					} else if net == `"udp"` {
-						data.udpCodes[key][k] = v
-					}
-				}
-			}
-		case "bodyLimit":
-			// v := strings.TrimSpace(kv[1])
-			// if v == "" {
-			// 	v = "0"
-			// }
-			data.httpCodes[key][k] = strings.TrimSpace(kv[1])
-		case "resp":
-			if v := strings.TrimSpace(kv[1]); v == `"object"` {
-				data.httpCodes[key][k] = "true"
-			}
-		case "validation":
-			if v := strings.TrimSpace(kv[1]); v == `"token"` {
-				data.httpCodes[key][k] = "net_fw.Validation_type_token"
-			} else if v == `"user"` {
-				if net == `"tcp"` {
-					data.tcpCodes[key][k] = "true"
-				} else if net == `"udp"` {
-					data.udpCodes[key][k] = "true"
-				}
-			}
-		case "dataPtrStruct":
-			if v := strings.TrimSpace(kv[1]); v != "" {
-				v = v[1 : len(v)-1] // 去掉两边引号
-				if v != "" {
-					vv := strings.Split(v, "|")
-					if net == `"http"` {
-						data.httpImports[fmt.Sprintf(`"%s"`, vv[0])] = fmt.Sprintf(`"%s"`, vv[0])
-						data.httpCodes[key][k] = fmt.Sprintf("func() any{ return &%s{}}", vv[1])
-					} else if net == `"tcp"` {
-						data.tcpImports[fmt.Sprintf(`"%s"`, vv[0])] = fmt.Sprintf(`"%s"`, vv[0])
-						data.tcpCodes[key][k] = fmt.Sprintf("func() any{ return &%s{}}", vv[1])
-					} else if net == `"udp"` {
-						data.udpImports[fmt.Sprintf(`"%s"`, vv[0])] = fmt.Sprintf(`"%s"`, vv[0])
-						data.udpCodes[key][k] = fmt.Sprintf("func() any{ return &%s{}}", vv[1])
-					}
-				}
-			}
-		case "bodyType":
-			if v := strings.TrimSpace(kv[1]); v != "" {
-				v = v[1 : len(v)-1] // 去掉两边引号
-				if v != "" {
-					if net == `"http"` {
-						data.httpCodes[key][k] = v
-					} else if net == `"tcp"` {
-						data.tcpCodes[key][k] = v
-					} else if net == `"udp"` {
-						data.udpCodes[key][k] = v
-					}
-				}
-			}
+// addSource 记录有注解的源文件，归到 key 所在的 net 生成的文件上
+func (data *templateData) addSource(filename, key string) {
+	for _, src := range []struct {
+		codes   map[string]map[string]string
+		sources *[]string
+	}{{data.httpCodes, &data.httpSources}, {data.tcpCodes, &data.tcpSources}, {data.udpCodes, &data.udpSources}} {
+		if src.codes[key] != nil && !slices.Contains(*src.sources, filename) {
+			*src.sources = append(*src.sources, filename)
 		}
 	}
 }
 
-// @Handler(type="valid.limit", pkg="", func="")
-func parseHandlerValid_limit(words []string, data *templateData, key string) {
-	if data.httpCodes[key] == nil {
-		data.httpCodes[key] = make(map[string]string)
-		data.httpCodesList = append(data.httpCodesList, key)
+// @Handler(type="api", net = "http/tcp", path = "/reg", bodyLimit = n, resp = "object", validation = "token")
+func parseHandlerApi(args map[string]string, data *templateData, key, apiExpr, apiName string) {
+	codes, list, imports := data.httpCodes, &data.httpCodesList, data.httpImports
+	switch args["net"] {
+	case "tcp":
+		codes, list, imports = data.tcpCodes, &data.tcpCodesList, data.tcpImports
+	case "udp":
+		codes, list, imports = data.udpCodes, &data.udpCodesList, data.udpImports
 	}
-	for _, ll := range words {
-		kv := strings.Split(ll, "=")
-		switch k := strings.TrimSpace(kv[0]); k {
-		case "pkg":
-			if v := strings.TrimSpace(kv[1]); len(v) > 2 {
-				data.httpImports[v] = strings.TrimSpace(kv[1])
-			}
-		case "func":
-			if f := strings.ReplaceAll(strings.TrimSpace(kv[1]), "\"", ""); f != "" {
-				data.httpCodes[key]["valid.limit"] = strings.ReplaceAll(strings.TrimSpace(kv[1]), "\"", "")
-			}
+	code := map[string]string{"api": apiExpr, "api_method": apiName}
+	codes[key] = code
+	*list = append(*list, key)
+	// 空值由生成注册代码时填默认值
+	if args["net"] == "http" {
+		code["net"] = "http"
+		code["path"] = args["path"]
+		code["method"] = strings.Join(splitMethods(args["method"]), "|")
+		code["params"] = args["params"]
+		code["bodyLimit"] = args["bodyLimit"]
+		if args["resp"] == "object" {
+			code["resp"] = "true"
 		}
+		if args["validation"] == "token" {
+			code["validation"] = "token"
+		}
+	} else {
+		code["msgId"] = args["msgId"]
+		if args["validation"] == "user" {
+			code["validation"] = "true"
+		}
+	}
+	if v := args["dataPtrStruct"]; v != "" {
+		importPath, typeName, _ := SplitDataPtrStruct(v) // parseHandlers 已经检查过
+		imports[strconv.Quote(importPath)] = strconv.Quote(importPath)
+		code["dataPtrStruct"] = fmt.Sprintf("func() any{ return &%s{}}", typeName)
 	}
+	code["bodyType"] = args["bodyType"]
+}
+
+// @Handler(type="valid.limit", pkg="", func="")
+func parseHandlerValid_limit(args map[string]string, data *templateData, key string) {
+	if pkg := args["pkg"]; pkg != "" {
+		data.httpImports[strconv.Quote(pkg)] = strconv.Quote(pkg)
+	}
+	data.httpCodes[key]["valid.limit"] = args["func"]
 }
 
 // @Handler(type="valid.file", pkg="", func="", headsize=n)
-func parseHandlerValid_file(words []string, data *templateData, key string) {
-	if data.httpCodes[key] == nil {
-		data.httpCodes[key] = make(map[string]string)
-		data.httpCodesList = append(data.httpCodesList, key)
+func parseHandlerValid_file(args map[string]string, data *templateData, key string) {
+	if pkg := args["pkg"]; pkg != "" {
+		data.httpImports[strconv.Quote(pkg)] = strconv.Quote(pkg)
 	}
-	funcstr, headsize := "", ""
-	for _, ll := range words {
-		kv := strings.Split(ll, "=")
-		switch k := strings.TrimSpace(kv[0]); k {
-		case "pkg":
-			if v := strings.TrimSpace(kv[1]); len(v) > 2 {
-				data.httpImports[v] = strings.TrimSpace(kv[1])
-			}
-		case "func":
-			funcstr = strings.ReplaceAll(strings.TrimSpace(kv[1]), "\"", "")
-		case "headsize":
-			headsize = strings.TrimSpace(kv[1])
-		}
+	headsize := args["headsize"]
+	if headsize == "" {
+		headsize = "0"
 	}
-	if funcstr != "" {
-		data.httpImports[`"github.com/valyala/fasthttp/zzz/mime/multipart"`] = `"github.com/valyala/fasthttp/zzz/mime/multipart"`
-		data.httpCodes[key]["valid.file"] = fmt.Sprintf("&multipart.MyValidHeader{ValidFormFileFormat: %s, ValidHeadSize: %s}", funcstr, headsize)
+	multipart := fmt.Sprintf(`multipart "%s"`, data.options.MultipartImport)
+	data.httpImports[multipart] = multipart
+	data.httpCodes[key]["valid.file"] = fmt.Sprintf("&multipart.MyValidHeader{ValidFormFileFormat: %s, ValidHeadSize: %s}", args["func"], headsize)
+}
+
+// checkHttpCodes 检查 backend 能否生成所有的 http 注册代码，错误带上注解的位置
+func (data *templateData) checkHttpCodes() error {
+	var errs generator.ErrorList
+	for _, key := range data.httpCodesList {
+		if _, err := data.httpBackend.register(data.httpCodes[key]); err != nil {
+			errs.Add(generator.Errorf(data.positions[key], "%s", err))
+		}
 	}
+	return errs.Err()
 }
 
 func GetImportsHttp(o templateData) string {
-	var str []string
-	for _, imp := range o.httpImports {
-		str = append(str, imp)
-	}
-	return strings.Join(str, "\n")
+	return strings.Join(o.httpBackend.imports(o), "\n")
 }
 
 func GetImportsTcp(o templateData) string {
@@ -309,27 +330,16 @@ func GetImportsUdp(o templateData) string {
 	return strings.Join(str, "\n")
 }
 
-func GetCodesHttp(o templateData) string {
+func GetCodesHttp(o templateData) (string, error) {
 	var strs []string
 	for _, cl := range o.httpCodesList {
-		mm := o.httpCodes[cl]
-		str := "net_fw.NewHtNetHandler("
-		for _, order := range httpApiOrders {
-			c := mm[order[0]]
-			if c == "" {
-				c = order[1]
-			} else {
-				if len(order) > 2 {
-					if order[2] == "str" {
-						c = "\"" + c + "\""
-					}
-				}
-			}
-			str += c + ","
+		str, err := o.httpBackend.register(o.httpCodes[cl])
+		if err != nil {
+			return "", fmt.Errorf("%s: %s", cl, err)
 		}
-		strs = append(strs, str[:len(str)-1]+")") // 去掉最后一个逗号
+		strs = append(strs, str)
 	}
-	return strings.Join(strs, "\n")
+	return strings.Join(strs, "\n"), nil
 }
 
 func GetCodesTcp(o templateData) string {
@@ -344,7 +354,7 @@ func GetCodesTcp(o templateData) string {
 			} else {
 				if len(order) > 2 {
 					if order[2] == "str" {
-						c = "\"" + c + "\""
+						c = strconv.Quote(c)
 					}
 				}
 			}
@@ -367,7 +377,7 @@ func GetCodesUdp(o templateData) string {
 			} else {
 				if len(order) > 2 {
 					if order[2] == "str" {
-						c = "\"" + c + "\""
+						c = strconv.Quote(c)
 					}
 				}
 			}
@@ -378,33 +388,25 @@ func GetCodesUdp(o templateData) string {
 	return strings.Join(strs, "\n")
 }
 
+// tcpUdpDoc tcp/udp 生成文件头部的说明
+var tcpUdpDoc = handlerDoc{Keys: []string{"net", "msgId", "dataPtrStruct", "validation", "bodyType"}}
+
 var customHttpTemplateFuncs = template.FuncMap{
 	"GetImportsHttp": GetImportsHttp,
 	"GetCodesHttp":   GetCodesHttp,
+	"HandlerDoc":     func(o templateData) string { return o.httpBackend.doc().String() },
 }
 
 var customTcpTemplateFuncs = template.FuncMap{
 	"GetImportsTcp": GetImportsTcp,
 	"GetCodesTcp":   GetCodesTcp,
+	"HandlerDoc":    tcpUdpDoc.String,
 }
 
 var customUdpTemplateFuncs = template.FuncMap{
 	"GetImportsUdp": GetImportsUdp,
 	"GetCodesUdp":   GetCodesUdp,
-}
-
-var httpApiOrders = [][]string{
-	{"net", ""},
-	{"path", ""},
-	{"resp", "false"},
-	{"validation", "net_fw.Validation_type_none"},
-	{"bodyLimit", "0"},
-	{"api", "nil"},
-	{"api_method", "", "str"},
-	{"valid.limit", "nil"},
-	{"valid.file", "nil"},
-	{"dataPtrStruct", "nil"},
-	{"bodyType", "0"},
+	"HandlerDoc":    tcpUdpDoc.String,
 }
 
 // msgId uint16, call func(*KcpRequestInfo) []fw_udp.Frame, callMethod string, takePtrStruct func() proto.Message
@@ -0,0 +1,289 @@
+package api
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const DefaultHttpBackend = "netfw"
+
+// httpBackend 把 net=http 的 @Handler 注解生成为某个框架的路由注册代码，
+// code 中的值为注解的原始含义(net="http"、validation="token" 等)，由 backend 翻译成对应框架的写法
+type httpBackend interface {
+	template() string
+	doc() handlerDoc
+	imports(o templateData) []string
+	register(code map[string]string) (string, error)
+}
+
+var httpBackends = map[string]httpBackend{
+	"netfw":    netFwBackend{},
+	"nethttp":  netHttpBackend{},
+	"fasthttp": fastHttpBackend{},
+}
+
+// HttpBackends 支持的 HttpBackend
+func HttpBackends() []string {
+	names := make([]string, 0, len(httpBackends))
+	for name := range httpBackends {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func (o Options) httpBackendFor(pkgName string) (httpBackend, error) {
+	name := o.PackageHttpBackends[pkgName]
+	if name == "" {
+		name = o.HttpBackend
+	}
+	if name == "" {
+		name = DefaultHttpBackend
+	}
+	if b, ok := httpBackends[name]; ok {
+		return b, nil
+	}
+	return nil, fmt.Errorf("package %s: unknown http backend %s (available: %s)", pkgName, name, strings.Join(HttpBackends(), ","))
+}
+
+func sortedImports(imports map[string]string) []string {
+	var str []string
+	for _, imp := range imports {
+		str = append(str, imp)
+	}
+	sort.Strings(str)
+	return str
+}
+
+// --------------------------------------------------- net_fw ---------------------------------------------------
+
+type netFwBackend struct{}
+
+var httpApiOrders = [][]string{
+	{"net", ""},
+	{"path", "", "str"},
+	{"resp", "false"},
+	{"validation", "net_fw.Validation_type_none"},
+	{"bodyLimit", "0"},
+	{"api", "nil"},
+	{"api_method", "", "str"},
+	{"valid.limit", "nil"},
+	{"valid.file", "nil"},
+	{"dataPtrStruct", "nil"},
+	{"bodyType", "0"},
+}
+
+var netFwValues = map[string]map[string]string{
+	"net":        {"http": "net_fw.HTNET_type_http"},
+	"validation": {"token": "net_fw.Validation_type_token"},
+}
+
+func (netFwBackend) template() string {
+	return httpHandlersTemplate
+}
+
+func (netFwBackend) doc() handlerDoc {
+	return handlerDoc{Keys: []string{"net", "path", "bodyLimit", "resp", "validation", "dataPtrStruct", "bodyType"}, Valid: true}
+}
+
+func (netFwBackend) imports(o templateData) []string {
+	netFw := fmt.Sprintf(`net_fw "%s"`, o.options.NetFwImport)
+	return append([]string{netFw}, sortedImports(o.httpImports)...)
+}
+
+func (netFwBackend) register(mm map[string]string) (string, error) {
+	if err := unsupported("netfw", mm, "method", "params"); err != nil { // net_fw 不支持按 method 注册和路径参数
+		return "", fmt.Errorf("%s, set api.httpBackend to nethttp or fasthttp", err)
+	}
+	str := "net_fw.NewHtNetHandler("
+	for _, order := range httpApiOrders {
+		c := mm[order[0]]
+		if c == "" {
+			c = order[1]
+		} else if v, ok := netFwValues[order[0]][c]; ok {
+			c = v
+		} else {
+			if len(order) > 2 {
+				if order[2] == "str" {
+					c = strconv.Quote(c)
+				}
+			}
+		}
+		str += c + ","
+	}
+	return str[:len(str)-1] + ")", nil // 去掉最后一个逗号
+}
+
+// --------------------------------------------------- net/http --------------------------------------------------
+
+// netHttpBackend 标准库 ServeMux(Go 1.22 的 "METHOD /path" 路由)，
+// handler 为 func(http.ResponseWriter, *http.Request)，自己处理请求体，不支持 resp、dataPtrStruct、bodyType
+type netHttpBackend struct{}
+
+func (netHttpBackend) template() string {
+	return httpNetHttpTemplate
+}
+
+func (netHttpBackend) doc() handlerDoc {
+	return handlerDoc{
+		Title: "(net/http)",
+		Keys:  []string{"path", "method", "params", "bodyLimit", "validation"},
+		Notes: []string{
+			"有路径参数时 handler 为 func(http.ResponseWriter, *http.Request, 参数...)，解析失败返回 400",
+			"没有默认的服务器配置，bodyLimit 为 0 时不限制；validation=\"token\" 的接口经过 tokenValidator 验证",
+			"不支持 resp、dataPtrStruct、bodyType，handler 自己处理请求体",
+		},
+	}
+}
+
+func (netHttpBackend) imports(o templateData) []string {
+	if needsStrconv(o) {
+		return []string{`"net/http"`, `"strconv"`}
+	}
+	return []string{`"net/http"`}
+}
+
+// register 有路径参数时 handler 为 func(http.ResponseWriter, *http.Request, 参数...)，参数由 r.PathValue 解析
+func (netHttpBackend) register(mm map[string]string) (string, error) {
+	if err := unsupported("nethttp", mm, "resp", "dataPtrStruct", "bodyType", "valid.limit", "valid.file"); err != nil {
+		return "", err
+	}
+	handler := fmt.Sprintf("http.HandlerFunc(%s)", mm["api"])
+	if params, _ := pathParams(mm["path"], mm["params"]); len(params) > 0 {
+		decls, args := bindParams(params, "r.PathValue(%q)", "http.Error(w, %q, http.StatusBadRequest)")
+		handler = fmt.Sprintf("http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {\n%s%s(w, r, %s)\n})", decls, mm["api"], args)
+	}
+	if limit, _ := strconv.Atoi(mm["bodyLimit"]); limit > 0 {
+		handler = fmt.Sprintf("http.MaxBytesHandler(%s, %d<<10)", handler, limit)
+	}
+	if mm["validation"] == "token" {
+		handler = fmt.Sprintf("tokenValidator(%s)", handler)
+	}
+	return registerMethods(mm["method"], handler, func(method, h string) string {
+		pattern := mm["path"]
+		if method != "" {
+			pattern = method + " " + pattern
+		}
+		return fmt.Sprintf("mux.Handle(%q, %s)", pattern, h)
+	}), nil
+}
+
+// --------------------------------------------------- fasthttp --------------------------------------------------
+
+// fastHttpBackend github.com/fasthttp/router，
+// handler 为 fasthttp.RequestHandler；不支持 bodyLimit(由 fasthttp.Server.MaxRequestBodySize 统一限制)、resp、dataPtrStruct、bodyType
+type fastHttpBackend struct{}
+
+func (fastHttpBackend) template() string {
+	return httpFastHttpTemplate
+}
+
+func (fastHttpBackend) doc() handlerDoc {
+	return handlerDoc{
+		Title: "(fasthttp router)",
+		Keys:  []string{"path", "method", "params", "validation"},
+		Notes: []string{
+			"有路径参数时 handler 为 func(*fasthttp.RequestCtx, 参数...)，解析失败返回 400",
+			"validation=\"token\" 的接口经过 tokenValidator 验证",
+			"不支持 bodyLimit(由 fasthttp.Server.MaxRequestBodySize 统一限制)、resp、dataPtrStruct、bodyType",
+		},
+	}
+}
+
+func (fastHttpBackend) imports(o templateData) []string {
+	imports := []string{`"github.com/fasthttp/router"`, `"github.com/valyala/fasthttp"`}
+	if needsStrconv(o) {
+		imports = append(imports, `"strconv"`)
+	}
+	return imports
+}
+
+// register 有路径参数时 handler 为 func(*fasthttp.RequestCtx, 参数...)，参数由 ctx.UserValue 解析
+func (fastHttpBackend) register(mm map[string]string) (string, error) {
+	if err := unsupported("fasthttp", mm, "bodyLimit", "resp", "dataPtrStruct", "bodyType", "valid.limit", "valid.file"); err != nil {
+		return "", err
+	}
+	handler := fmt.Sprintf("fasthttp.RequestHandler(%s)", mm["api"])
+	if params, _ := pathParams(mm["path"], mm["params"]); len(params) > 0 {
+		decls, args := bindParams(params, "ctx.UserValue(%q).(string)", "ctx.Error(%q, fasthttp.StatusBadRequest)")
+		handler = fmt.Sprintf("fasthttp.RequestHandler(func(ctx *fasthttp.RequestCtx) {\n%s%s(ctx, %s)\n})", decls, mm["api"], args)
+	}
+	if mm["validation"] == "token" {
+		handler = fmt.Sprintf("tokenValidator(%s)", handler)
+	}
+	return registerMethods(mm["method"], handler, func(method, h string) string {
+		m := "router.MethodWild"
+		if method != "" {
+			m = strconv.Quote(method)
+		}
+		return fmt.Sprintf("r.Handle(%s, %q, %s)", m, mm["path"], h)
+	}), nil
+}
+
+// registerMethods 每个 method 注册一次；多个 method 时 handler 只创建一次
+func registerMethods(method, handler string, line func(method, h string) string) string {
+	methods := splitMethods(method)
+	if len(methods) <= 1 {
+		return line(method, handler)
+	}
+	lines := []string{"{", "h := " + handler}
+	for _, m := range methods {
+		lines = append(lines, line(m, "h"))
+	}
+	return strings.Join(append(lines, "}"), "\n")
+}
+
+// bindParams 解析路径参数的语句以及传给 handler 的参数；value 为取参数值的表达式，fail 为解析失败时的语句
+func bindParams(params []PathParam, value, fail string) (decls, args string) {
+	var names []string
+	for _, p := range params {
+		v := fmt.Sprintf(value, p.Name)
+		names = append(names, p.Name)
+		var parse string
+		switch p.Type {
+		case "int":
+			parse = fmt.Sprintf("strconv.Atoi(%s)", v)
+		case "int64":
+			parse = fmt.Sprintf("strconv.ParseInt(%s, 10, 64)", v)
+		case "uint64":
+			parse = fmt.Sprintf("strconv.ParseUint(%s, 10, 64)", v)
+		case "bool":
+			parse = fmt.Sprintf("strconv.ParseBool(%s)", v)
+		default:
+			decls += fmt.Sprintf("%s := %s\n", p.Name, v)
+			continue
+		}
+		decls += fmt.Sprintf("%s, err := %s\nif err != nil {\n%s\nreturn\n}\n", p.Name, parse, fmt.Sprintf(fail, "invalid path parameter "+p.Name))
+	}
+	return decls, strings.Join(names, ", ")
+}
+
+// needsStrconv 有非 string 的路径参数时生成的代码需要 strconv
+func needsStrconv(o templateData) bool {
+	for _, code := range o.httpCodes {
+		params, _ := pathParams(code["path"], code["params"])
+		for _, p := range params {
+			if p.Type != "string" {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// unsupported 注解中使用了 backend 无法生成的参数或 valid.limit/valid.file 时返回错误，空值和 0(默认值)不算使用
+func unsupported(backend string, mm map[string]string, keys ...string) error {
+	for _, k := range keys {
+		if v := mm[k]; v == "" || v == "0" {
+			continue
+		}
+		what := k
+		if strings.HasPrefix(k, "valid.") { // 同一函数上的 @Handler(type="valid.xxx")
+			what = fmt.Sprintf("@Handler(type=%q)", k)
+		}
+		return fmt.Errorf("%s is not supported by the %s http backend", what, backend)
+	}
+	return nil
+}
@@ -0,0 +1,169 @@
+package api
+
+import (
+	"errors"
+	"path/filepath"
+	"sort"
+
+	"github.com/bwb0101/goAnnotations/annotation"
+	"github.com/bwb0101/goAnnotations/generator"
+	"github.com/bwb0101/goAnnotations/model"
+)
+
+// Handler 是一个 @Handler(type="api", ...) 注解对外暴露的注册信息
+type Handler struct {
+	Operation     model.Operation
+	Net           string            // http/tcp/udp
+	Path          string            // net=http
+	Method        string            // net=http，GET|POST，空为不限制
+	Params        string            // net=http，路径参数的类型 id:int64
+	MsgId         string            // net=tcp/udp
+	DataPtrStruct string            // "path|pkg.struct"
+	Args          map[string]string // 注解的全部参数
+}
+
+// Key 返回注解所在方法在包中的标识: pkg.func 或 pkg.struct.func，跨目录比较时用 KeyIn
+func (h Handler) Key() string {
+	if h.Operation.RelatedStruct != nil {
+		return h.Operation.PackageName + "." + h.Operation.RelatedStruct.DereferencedTypeName() + "." + h.Operation.Name
+	}
+	return h.Operation.PackageName + "." + h.Operation.Name
+}
+
+// KeyIn 在 Key 前加上所在目录相对于 root 的路径，例如 user/v2/user.Get，多个目录中有同名的包时不会重复
+func (h Handler) KeyIn(root string) string {
+	rel, err := filepath.Rel(root, filepath.Dir(h.Operation.Filename))
+	if err != nil || rel == "." {
+		return h.Key()
+	}
+	return filepath.ToSlash(rel) + "/" + h.Key()
+}
+
+// Methods method="GET|POST" 中的方法，不限制时为空
+func (h Handler) Methods() []string {
+	return splitMethods(h.Method)
+}
+
+// PathParams path 中的参数以及 params 中声明的类型，注解不合法时为空
+func (h Handler) PathParams() []PathParam {
+	params, _ := pathParams(h.Path, h.Params)
+	return params
+}
+
+// ExtractHandlers 所有 @Handler(type="api")，与 api 生成器一样检查注解：
+// 不合法时返回带位置的 generator.ErrorList，不合法的函数上的 handler 不返回
+func ExtractHandlers(operations []model.Operation) ([]Handler, error) {
+	var handlers []Handler
+	var errs generator.ErrorList
+	for _, op := range operations {
+		as, err := parseHandlers(op)
+		if err != nil {
+			errs.Add(err)
+			continue
+		}
+		for _, a := range as {
+			if a.Args["type"] != "api" {
+				continue
+			}
+			handlers = append(handlers, Handler{
+				Operation:     op,
+				Net:           a.Args["net"],
+				Path:          a.Args["path"],
+				Method:        a.Args["method"],
+				Params:        a.Args["params"],
+				MsgId:         a.Args["msgId"],
+				DataPtrStruct: a.Args["dataPtrStruct"],
+				Args:          a.Args,
+			})
+		}
+	}
+	return handlers, errs.Err()
+}
+
+// parseHandlers 解析并检查 op 上的所有 @Handler，type="api" 排在最前面；
+// 格式错误、参数不合法时返回带位置的 generator.ErrorList
+func parseHandlers(op model.Operation) ([]annotation.Annotation, error) {
+	var handlers []annotation.Annotation
+	var errs generator.ErrorList
+	var lines []int // handlers 所在的 DocLines 下标
+	pos := func(i, offset int) generator.Position {
+		return docPos(op, i, offset)
+	}
+	for i, line := range op.DocLines {
+		a, ok, err := annotation.Parse(line)
+		if !ok || a.Name != HandlerSchema.Name {
+			continue
+		}
+		var se *annotation.SyntaxError
+		if errors.As(err, &se) {
+			errs.Add(generator.Errorf(pos(i, se.Offset), "%s", se.Msg))
+			continue
+		}
+		if verrs := HandlerSchema.Validate(a); len(verrs) > 0 {
+			for _, err := range verrs {
+				if errors.As(err, &se) {
+					errs.Add(generator.Errorf(pos(i, se.Offset), "%s", se.Msg))
+				}
+			}
+			continue
+		}
+		handlers = append(handlers, a)
+		lines = append(lines, i)
+	}
+	// 参数之间的关系
+	var api *annotation.Annotation
+	for n := range handlers {
+		a := &handlers[n]
+		argPos := func(key string) generator.Position {
+			for _, arg := range a.List {
+				if arg.Key == key {
+					return pos(lines[n], arg.ValuePos)
+				}
+			}
+			return pos(lines[n], a.Pos)
+		}
+		switch a.Args["type"] {
+		case "api":
+			if api != nil {
+				errs.Add(generator.Errorf(pos(lines[n], a.Pos), "duplicate @Handler(type=\"api\")"))
+			}
+			api = a
+			switch a.Args["net"] {
+			case "":
+				errs.Add(generator.Errorf(pos(lines[n], a.Pos), "@Handler(type=\"api\") requires net"))
+			case "http":
+				if a.Args["path"] == "" {
+					errs.Add(generator.Errorf(argPos("path"), "net=\"http\" requires path"))
+				} else if _, err := pathParams(a.Args["path"], a.Args["params"]); err != nil {
+					key := "path"
+					if _, ok := a.Args["params"]; ok {
+						key = "params"
+					}
+					errs.Add(generator.Errorf(argPos(key), "%s", err))
+				}
+			default:
+				for _, key := range []string{"method", "params"} {
+					if _, ok := a.Args[key]; ok {
+						errs.Add(generator.Errorf(argPos(key), "%s requires net=\"http\"", key))
+					}
+				}
+			}
+		default: // valid.limit/valid.file
+			if a.Args["func"] == "" {
+				errs.Add(generator.Errorf(argPos("func"), "@Handler(type=%q) requires func", a.Args["type"]))
+			}
+		}
+	}
+	for n, a := range handlers {
+		if t := a.Args["type"]; t != "api" && (api == nil || api.Args["net"] != "http") {
+			errs.Add(generator.Errorf(pos(lines[n], a.Pos), "@Handler(type=%q) requires @Handler(type=\"api\", net=\"http\") on the same function", t))
+		}
+	}
+	if err := errs.Err(); err != nil {
+		return nil, err
+	}
+	sort.SliceStable(handlers, func(i, j int) bool {
+		return handlers[i].Args["type"] == "api" && handlers[j].Args["type"] != "api"
+	})
+	return handlers, nil
+}
@@ -0,0 +1,185 @@
+package api
+
+import (
+	"fmt"
+	"go/token"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/bwb0101/goAnnotations/annotation"
+	"github.com/bwb0101/goAnnotations/generator"
+	"github.com/bwb0101/goAnnotations/model"
+)
+
+// routeTable 检查 http method+path、tcp msgId、udp msgId 在所有包中是否唯一，以及 msgId 是否在包的范围内
+type routeTable struct {
+	seen   map[string]route            // net + " " + msgId
+	paths  map[string]map[string]route // 去掉参数名的 path -> method(不限制时为 *) ->
+	ranges map[string]msgIdRange
+}
+
+type route struct {
+	handler string // pkg.func 或 pkg.struct.func
+	pos     generator.Position
+}
+
+type msgIdRange struct {
+	min, max uint64
+}
+
+func newRouteTable(ranges map[string]string) (*routeTable, error) {
+	t := &routeTable{seen: map[string]route{}, paths: map[string]map[string]route{}, ranges: map[string]msgIdRange{}}
+	for pkgName, s := range ranges {
+		if s == "" {
+			continue
+		}
+		r, err := parseMsgIdRange(s)
+		if err != nil {
+			return nil, fmt.Errorf("package %s: %s", pkgName, err)
+		}
+		t.ranges[pkgName] = r
+	}
+	return t, nil
+}
+
+// parseMsgIdRange "1000-1999"，两端都包含
+func parseMsgIdRange(s string) (msgIdRange, error) {
+	lo, hi, ok := strings.Cut(s, "-")
+	min, err1 := strconv.ParseUint(strings.TrimSpace(lo), 10, 16)
+	max, err2 := strconv.ParseUint(strings.TrimSpace(hi), 10, 16)
+	if !ok || err1 != nil || err2 != nil || min > max {
+		return msgIdRange{}, fmt.Errorf("invalid msgIdRange %q, expected min-max", s)
+	}
+	return msgIdRange{min: min, max: max}, nil
+}
+
+// add 记录 op 上 type="api" 的注册信息，与已有的冲突时返回带两个位置的错误
+func (t *routeTable) add(op model.Operation, handlers []annotation.Annotation) error {
+	for _, a := range handlers {
+		if a.Args["type"] != "api" {
+			continue
+		}
+		net := a.Args["net"]
+		var key string
+		switch net {
+		case "http":
+			return t.addHttp(op, a)
+		case "tcp", "udp":
+			msgId := a.Args["msgId"]
+			if msgId == "" {
+				return nil
+			}
+			if r, ok := t.ranges[op.PackageName]; ok {
+				if id, _ := strconv.ParseUint(msgId, 10, 16); id < r.min || id > r.max {
+					return generator.Errorf(handlerPos(op), "%s msgId %s is outside the range %d-%d of package %s", net, msgId, r.min, r.max, op.PackageName)
+				}
+			}
+			key = net + " " + msgId
+		default:
+			return nil
+		}
+		cur := route{handler: Handler{Operation: op}.Key(), pos: handlerPos(op)}
+		if prev, ok := t.seen[key]; ok {
+			return generator.Errorf(cur.pos, "%s msgId %s of %s is already registered by %s at %s", net, a.Args["msgId"], cur.handler, prev.handler, prev.pos)
+		}
+		t.seen[key] = cur
+	}
+	return nil
+}
+
+// addHttp /user/{id} 和 /user/{uid} 是同一个路由；不限制 method 的与同一 path 的所有 method 冲突
+func (t *routeTable) addHttp(op model.Operation, a annotation.Annotation) error {
+	path := pathParamPattern.ReplaceAllString(a.Args["path"], "{}")
+	methods := splitMethods(a.Args["method"])
+	if len(methods) == 0 {
+		methods = []string{"*"}
+	}
+	registered := t.paths[path]
+	if registered == nil {
+		registered = map[string]route{}
+		t.paths[path] = registered
+	}
+	cur := route{handler: Handler{Operation: op}.Key(), pos: handlerPos(op)}
+	for _, m := range methods {
+		prev, ok := registered[m]
+		if !ok && m != "*" {
+			prev, ok = registered["*"]
+		}
+		if !ok && m == "*" {
+			for _, hm := range httpMethods { // 按固定的顺序，报告的位置稳定
+				if prev, ok = registered[hm]; ok {
+					break
+				}
+			}
+		}
+		if ok {
+			return generator.Errorf(cur.pos, "http %s %s of %s is already registered by %s at %s", m, a.Args["path"], cur.handler, prev.handler, prev.pos)
+		}
+	}
+	for _, m := range methods {
+		registered[m] = cur
+	}
+	return nil
+}
+
+// PathParam path 中的 {name} 参数，见 Handler.PathParams
+type PathParam struct {
+	Name string
+	Type string // pathParamTypes 之一，默认 string
+}
+
+var pathParamPattern = regexp.MustCompile(`\{([^{}]*)\}`)
+
+var pathParamTypes = []string{"string", "int", "int64", "uint64", "bool"}
+
+// reservedParamNames 生成的代码中已经使用的名字
+var reservedParamNames = []string{"w", "r", "ctx", "err", "h", "http", "fasthttp", "strconv"}
+
+// pathParams path 中的参数，按出现的顺序；类型取自 params="id:int64,name"
+func pathParams(path, params string) ([]PathParam, error) {
+	types := map[string]string{}
+	var declared []string
+	for _, p := range strings.Split(params, ",") {
+		if p = strings.TrimSpace(p); p == "" {
+			continue
+		}
+		name, typ, _ := strings.Cut(p, ":")
+		name, typ = strings.TrimSpace(name), strings.TrimSpace(typ)
+		if typ == "" {
+			typ = "string"
+		}
+		if !slices.Contains(pathParamTypes, typ) {
+			return nil, fmt.Errorf("unknown type %s of path parameter %s (available: %s)", typ, name, strings.Join(pathParamTypes, ","))
+		}
+		if _, ok := types[name]; ok {
+			return nil, fmt.Errorf("duplicate path parameter %s in params", name)
+		}
+		types[name] = typ
+		declared = append(declared, name)
+	}
+	var list []PathParam
+	for _, m := range pathParamPattern.FindAllStringSubmatch(path, -1) {
+		name := m[1]
+		switch {
+		case !token.IsIdentifier(name):
+			return nil, fmt.Errorf("invalid path parameter {%s}", name)
+		case slices.Contains(reservedParamNames, name):
+			return nil, fmt.Errorf("path parameter name %s is reserved", name)
+		case slices.ContainsFunc(list, func(p PathParam) bool { return p.Name == name }):
+			return nil, fmt.Errorf("duplicate path parameter {%s}", name)
+		}
+		typ := types[name]
+		if typ == "" {
+			typ = "string"
+		}
+		list = append(list, PathParam{Name: name, Type: typ})
+	}
+	for _, name := range declared {
+		if !slices.ContainsFunc(list, func(p PathParam) bool { return p.Name == name }) {
+			return nil, fmt.Errorf("params declares %s but path has no {%s}", name, name)
+		}
+	}
+	return list, nil
+}
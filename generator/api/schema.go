@@ -0,0 +1,163 @@
+package api
+
+import (
+	"fmt"
+	"go/token"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/bwb0101/goAnnotations/annotation"
+)
+
+// HandlerSchema @Handler 的参数说明，生成文件头部的说明也由它生成(见 handlerDoc)
+var HandlerSchema = annotation.Schema{
+	Name: "@Handler",
+	Doc: "API注册\n" +
+		"@Handler(type=\"api\", net=\"http\", path=\"/reg\", bodyLimit=n, resp=\"object\", validation=\"token\", dataPtrStruct=\"path|pkg.struct\", bodyType=\"0/1\")\n" +
+		"@Handler(type=\"api\", net=\"http\", method=\"GET|POST\", path=\"/user/{id}\", params=\"id:int64\") (httpBackend 为 nethttp/fasthttp)\n" +
+		"@Handler(type=\"api\", net=\"tcp/udp\", msgId=\"uint16\", dataPtrStruct=\"path|pkg.struct\", validation=\"user\", bodyType=\"0/1\")\n\n" +
+		"访问限制\n" +
+		"@Handler(type=\"valid.limit\", pkg=\"\", func=\"\")\n\n" +
+		"上传文件时验证文件头是否合法\n" +
+		"@Handler(type=\"valid.file\", pkg=\"\", func=\"\", headsize=n)",
+	Keys: []annotation.Key{
+		{Name: "type", Doc: "api：API注册；valid.limit：访问限制；valid.file：上传文件时验证文件头是否合法", Values: []string{"api", "valid.limit", "valid.file"}, Required: true},
+		{Name: "net", Doc: "http/tcp/udp：根据net类型分类处理", Values: []string{"http", "tcp", "udp"}},
+		{Name: "path", Doc: "/xxx：请求路径，{name} 为路径参数；net=http有效"},
+		{Name: "method", Doc: "GET|POST：允许的请求方法，| 分隔，不写时不限制；net=http 且 httpBackend 为 nethttp/fasthttp 时有效", Check: checkMethods},
+		{Name: "params", Doc: "id:int64,name：路径参数的类型(string/int/int64/uint64/bool)，不写为 string；生成的代码解析后传给 handler；net=http 且 httpBackend 为 nethttp/fasthttp 时有效"},
+		{Name: "msgId", Doc: "uint16数值；net=tcp/udp有效", Check: checkUint(16)},
+		{Name: "bodyLimit", Doc: "n：当前请求体限制(k) 0 = 默认服务器配置；net=http有效", Check: checkUint(32)},
+		{Name: "resp", Doc: "object：返回的对象需要进行序列化；net=http有效", Values: []string{"object"}},
+		{Name: "validation", Doc: "token：需要验证token(net=http)；user：检测Connect->UserValue是否为nil(net=tcp/udp)；空或不写：忽略验证", Values: []string{"token", "user", ""}},
+		{Name: "dataPtrStruct", Doc: "path|pkg.struct：path = import的路径；pkg.struct = 反序列化时的包名结构体", Check: checkDataPtrStruct},
+		{Name: "bodyType", Doc: "0/1：默认0，1 framebody类型", Values: []string{"0", "1"}},
+		{Name: "pkg", Doc: "包名: xxx/xxx；type=valid.limit/valid.file有效"},
+		{Name: "func", Doc: "方法名: xxx.xxx；type=valid.limit/valid.file有效", Check: checkFuncName},
+		{Name: "headsize", Doc: "验证文件头大小: body[:headsize]；type=valid.file有效", Check: checkUint(32)},
+	},
+}
+
+func init() {
+	annotation.Register(HandlerSchema)
+}
+
+// handlerDoc 生成文件头部的 @Handler 说明: HandlerSchema.Doc 中每段的标题和写法，以及写法中参数的说明
+type handlerDoc struct {
+	Title string   // 附加在第一段的标题后，例如 (net/http)
+	Keys  []string // type="api" 时说明的参数，只保留参数(type、net 除外)都在其中的写法
+	Valid bool     // 是否说明 valid.limit/valid.file
+	Notes []string // 生成代码特有的说明，放在 type="api" 的参数说明之后
+}
+
+func (d handlerDoc) accepts(a annotation.Annotation) bool {
+	if a.Args["type"] != "api" {
+		return d.Valid
+	}
+	for k := range a.Args {
+		if k != "type" && k != "net" && !slices.Contains(d.Keys, k) {
+			return false
+		}
+	}
+	return true
+}
+
+func (d handlerDoc) String() string {
+	var sections []string
+	for _, section := range strings.Split(HandlerSchema.Doc, "\n\n") {
+		var titles, examples []string
+		used := map[string]bool{}
+		isApi := false
+		for _, line := range strings.Split(section, "\n") {
+			a, ok := annotation.ParseLine("// " + line)
+			if !ok {
+				titles = append(titles, line)
+				continue
+			}
+			if d.accepts(a) {
+				examples = append(examples, line)
+				isApi = a.Args["type"] == "api"
+				for k := range a.Args {
+					used[k] = true
+				}
+			}
+		}
+		if len(examples) == 0 {
+			continue
+		}
+		if len(sections) == 0 && len(titles) > 0 {
+			titles[0] += d.Title
+		}
+		lines := append(titles, examples...)
+		for _, k := range HandlerSchema.Keys {
+			if k.Name != "type" && (isApi && slices.Contains(d.Keys, k.Name) || !isApi && used[k.Name]) {
+				lines = append(lines, k.Name+" = "+k.Doc)
+			}
+		}
+		if isApi {
+			lines = append(lines, d.Notes...)
+		}
+		sections = append(sections, strings.Join(lines, "\n"))
+	}
+	return strings.Join(sections, "\n\n")
+}
+
+var httpMethods = []string{"GET", "HEAD", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"}
+
+// splitMethods "GET|POST" -> [GET POST]
+func splitMethods(v string) []string {
+	var methods []string
+	for _, m := range strings.Split(v, "|") {
+		if m = strings.TrimSpace(m); m != "" {
+			methods = append(methods, m)
+		}
+	}
+	return methods
+}
+
+func checkMethods(v string) error {
+	methods := splitMethods(v)
+	if len(methods) == 0 {
+		return fmt.Errorf("%q has no method", v)
+	}
+	for _, m := range methods {
+		if !slices.Contains(httpMethods, m) {
+			return fmt.Errorf("unknown method %s (available: %s)", m, strings.Join(httpMethods, "|"))
+		}
+	}
+	return nil
+}
+
+func checkUint(bitSize int) func(string) error {
+	return func(v string) error {
+		if _, err := strconv.ParseUint(v, 10, bitSize); err != nil {
+			return fmt.Errorf("%q is not a uint%d", v, bitSize)
+		}
+		return nil
+	}
+}
+
+// SplitDataPtrStruct 把 "path|pkg.struct" 拆成 import 路径和 pkg.struct
+func SplitDataPtrStruct(v string) (importPath, typeName string, err error) {
+	importPath, typeName, ok := strings.Cut(v, "|")
+	if !ok || importPath == "" || !strings.Contains(typeName, ".") {
+		return "", "", fmt.Errorf("%q is not in the form path|pkg.struct", v)
+	}
+	return importPath, typeName, nil
+}
+
+// checkFuncName func 原样写入生成的代码，只允许 pkg.Func 这样的名字
+func checkFuncName(v string) error {
+	for _, part := range strings.Split(v, ".") {
+		if !token.IsIdentifier(part) {
+			return fmt.Errorf("%q is not a function name", v)
+		}
+	}
+	return nil
+}
+
+func checkDataPtrStruct(v string) error {
+	_, _, err := SplitDataPtrStruct(v)
+	return err
+}
@@ -0,0 +1,185 @@
+package api
+
+import (
+	"fmt"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"text/template"
+
+	"github.com/bwb0101/goAnnotations/annotation"
+	"github.com/bwb0101/goAnnotations/generator"
+	"github.com/bwb0101/goAnnotations/generator/util"
+	"github.com/bwb0101/goAnnotations/model"
+)
+
+// FuncMode 哪些函数上的 @Handler 会被处理
+type FuncMode string
+
+const (
+	FuncsAll    FuncMode = "all"    // 默认
+	FuncsStatic FuncMode = "static" // 只处理非 struct 的函数
+	FuncsMethod FuncMode = "method" // 只处理 struct 的方法，receiver 由 struct 上的 @Service 提供
+)
+
+var FuncModes = []FuncMode{FuncsAll, FuncsStatic, FuncsMethod}
+
+func (m FuncMode) check() error {
+	if m == "" || slices.Contains(FuncModes, m) {
+		return nil
+	}
+	return fmt.Errorf("unknown funcs mode %s (available: all,static,method)", m)
+}
+
+func (m FuncMode) includes(op model.Operation) bool {
+	switch m {
+	case FuncsStatic:
+		return op.RelatedStruct == nil
+	case FuncsMethod:
+		return op.RelatedStruct != nil
+	}
+	return true
+}
+
+// ServiceSchema struct 上的 @Service，方法上的 @Handler 注册时绑定到它创建的实例
+var ServiceSchema = annotation.Schema{
+	Name: "@Service",
+	Doc: "方法 handler 的 receiver\n" +
+		"@Service(constructor=\"NewXxx\")\n" +
+		"constructor 为同一个包中返回 *Xxx(或 Xxx)的无参函数；不写时使用 &Xxx{}",
+	Keys: []annotation.Key{
+		{Name: "constructor", Doc: "创建 receiver 的无参函数，不写时使用 &Xxx{}"},
+	},
+}
+
+func init() {
+	annotation.Register(ServiceSchema)
+}
+
+// service 生成代码中 receiver 变量的声明
+type service struct {
+	Var      string // svcXxx
+	Expr     string // NewXxx() 或 &Xxx{}
+	Filename string // struct 所在的文件
+}
+
+// findServices 带 @Service 的 struct，key 为 目录|包名|struct名
+func findServices(structs []model.Struct) map[string]service {
+	services := map[string]service{}
+	for _, st := range structs {
+		a, ok := annotation.Find(st.DocLines, ServiceSchema.Name)
+		if !ok {
+			continue
+		}
+		expr := "&" + st.Name + "{}"
+		if c := a.Args["constructor"]; c != "" {
+			expr = c + "()"
+		}
+		services[serviceKey(filepath.Dir(st.Filename), st.PackageName, st.Name)] = service{Var: "svc" + st.Name, Expr: expr, Filename: st.Filename}
+	}
+	return services
+}
+
+func serviceKey(dir, pkg, name string) string {
+	return dir + "|" + pkg + "|" + name
+}
+
+// handlerFunc 注册时引用的 handler，方法为 svcXxx.Method
+func handlerFunc(op model.Operation, targetDir string, services map[string]service) (expr string, svc *service, err error) {
+	if op.RelatedStruct == nil {
+		return op.Name, nil, nil
+	}
+	recv := op.RelatedStruct.DereferencedTypeName()
+	s, ok := services[serviceKey(targetDir, op.PackageName, recv)]
+	if !ok {
+		return "", nil, fmt.Errorf("method %s.%s has @Handler but struct %s has no @Service annotation", recv, op.Name, recv)
+	}
+	return s.Var + "." + op.Name, &s, nil
+}
+
+// addService 记录需要声明的 receiver，同一个包中的 http/tcp/udp handler 共用一个实例
+func (data *templateData) addService(svc *service) {
+	if svc == nil {
+		return
+	}
+	data.services[svc.Var] = svc.Expr
+	if !slices.Contains(data.serviceSources, svc.Filename) {
+		data.serviceSources = append(data.serviceSources, svc.Filename)
+	}
+}
+
+func generate_services(datas map[string]*templateData) ([]generator.File, error) {
+	var files []generator.File
+	for _, data := range datas {
+		if len(data.services) > 0 {
+			f, err := util.Generate(util.Info{
+				Data:           *data,
+				Sources:        data.serviceSources,
+				TargetFilename: filepath.Join(data.TargetDir, generator.GenfilePrefix+"api_services.go"),
+				TemplateName:   "api_services",
+				TemplateString: servicesTemplate,
+				FuncMap:        template.FuncMap{"GetServices": GetServices},
+			})
+			if err != nil {
+				return nil, err
+			}
+			files = append(files, f)
+		}
+	}
+	return files, nil
+}
+
+// GetServices receiver 变量的声明，按变量名排序
+func GetServices(o templateData) string {
+	decls := make([]string, 0, len(o.services))
+	for v, expr := range o.services {
+		decls = append(decls, fmt.Sprintf("%s = %s", v, expr))
+	}
+	sort.Strings(decls)
+	return strings.Join(decls, "\n")
+}
+
+const servicesTemplate = `package {{.PackageName}}
+
+// 带 @Handler 的方法注册时使用的 receiver，由 struct 上的 @Service 创建
+var (
+	{{GetServices .}}
+)
+`
+
+// operationKey 同一个文件中不同 struct 的同名方法不能冲突
+func operationKey(op model.Operation) string {
+	if op.RelatedStruct != nil {
+		return op.Filename + op.RelatedStruct.DereferencedTypeName() + "." + op.Name
+	}
+	return op.Filename + op.Name
+}
+
+func isHandler(op model.Operation) bool {
+	return annotation.Has(op.DocLines, HandlerSchema.Name)
+}
+
+// handlerPos type="api" 的 @Handler 所在的位置，没有时为第一个 @Handler
+func handlerPos(op model.Operation) generator.Position {
+	pos := generator.Position{Filename: op.Filename}
+	found := false
+	for i, line := range op.DocLines {
+		if a, ok := annotation.ParseLine(line); ok && a.Name == HandlerSchema.Name && (!found || a.Args["type"] == "api") {
+			pos, found = docPos(op, i, a.Pos), true
+			if a.Args["type"] == "api" {
+				break
+			}
+		}
+	}
+	return pos
+}
+
+// docPos op.DocLines[i] 中 offset 处的位置，解析时没有记录行号时只有文件名
+func docPos(op model.Operation, i, offset int) generator.Position {
+	pos := generator.Position{Filename: op.Filename}
+	if op.DocLine > 0 {
+		pos.Line, pos.Col = op.DocLine+i, offset+1
+	}
+	return pos
+}
@@ -0,0 +1,105 @@
+package generator
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Position 错误在源文件中的位置，Line/Col 从 1 开始，为 0 时表示未知
+type Position struct {
+	Filename string
+	Line     int
+	Col      int
+}
+
+func (p Position) String() string {
+	s := p.Filename
+	if p.Line > 0 {
+		s += fmt.Sprintf(":%d", p.Line)
+		if p.Col > 0 {
+			s += fmt.Sprintf(":%d", p.Col)
+		}
+	}
+	return s
+}
+
+// Error 生成器报告的一个错误
+type Error struct {
+	Generator string // 由运行生成器的一方填写
+	Pos       Position
+	Msg       string
+}
+
+func (e *Error) Error() string {
+	var sb strings.Builder
+	if pos := e.Pos.String(); pos != "" {
+		sb.WriteString(pos + ": ")
+	}
+	if e.Generator != "" {
+		sb.WriteString(e.Generator + ": ")
+	}
+	sb.WriteString(e.Msg)
+	return sb.String()
+}
+
+// Errorf 在 pos 处的错误
+func Errorf(pos Position, format string, args ...any) *Error {
+	return &Error{Pos: pos, Msg: fmt.Sprintf(format, args...)}
+}
+
+// ErrorList 生成器可以把所有错误收集起来一起返回，而不是遇到第一个错误就停止
+type ErrorList []*Error
+
+// Add 加入 err；err 为 ErrorList 时展开，不是 *Error 时作为没有位置的错误
+func (l *ErrorList) Add(err error) {
+	if err == nil {
+		return
+	}
+	var list ErrorList
+	var e *Error
+	switch {
+	case errors.As(err, &list):
+		*l = append(*l, list...)
+	case errors.As(err, &e):
+		*l = append(*l, e)
+	default:
+		*l = append(*l, &Error{Msg: err.Error()})
+	}
+}
+
+// Sort 按文件、行、列、生成器排序
+func (l ErrorList) Sort() {
+	sort.SliceStable(l, func(i, j int) bool {
+		a, b := l[i], l[j]
+		if a.Pos.Filename != b.Pos.Filename {
+			return a.Pos.Filename < b.Pos.Filename
+		}
+		if a.Pos.Line != b.Pos.Line {
+			return a.Pos.Line < b.Pos.Line
+		}
+		if a.Pos.Col != b.Pos.Col {
+			return a.Pos.Col < b.Pos.Col
+		}
+		return a.Generator < b.Generator
+	})
+}
+
+func (l ErrorList) Error() string {
+	switch len(l) {
+	case 0:
+		return "no errors"
+	case 1:
+		return l[0].Error()
+	}
+	return fmt.Sprintf("%s (and %d more errors)", l[0], len(l)-1)
+}
+
+// Err 没有错误时返回 nil
+func (l ErrorList) Err() error {
+	if len(l) == 0 {
+		return nil
+	}
+	return l
+}
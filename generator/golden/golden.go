@@ -0,0 +1,237 @@
+// Package golden 生成器的 golden 文件测试。
+//
+// 目录结构为 <root>/<生成器名>/<用例名>/input/*.go 和 <root>/<生成器名>/<用例名>/expected/...，
+// 生成器以 input 为输入目录运行，输出的文件(相对于 input 的路径)与 expected 中的文件逐一比较。
+// 生成器返回错误时，错误信息与 expected/errors.txt 比较。
+// 用例目录中可以放 goannotations.yaml 作为该用例的配置，与 generate 命令一样只解析 input 目录(不包括子目录)。
+// update 为 true 时用生成结果覆盖 expected
+package golden
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/bwb0101/goAnnotations/config"
+	"github.com/bwb0101/goAnnotations/generator"
+	"github.com/bwb0101/goAnnotations/parser"
+)
+
+const (
+	InputDir    = "input"
+	ExpectedDir = "expected"
+	ErrorsFile  = "errors.txt" // 生成器返回错误时，expected 中为错误信息
+)
+
+type Case struct {
+	Generator string // 生成器名，即用例的上一级目录名
+	Name      string
+	Dir       string
+}
+
+func (c Case) String() string {
+	return c.Generator + "/" + c.Name
+}
+
+func (c Case) InputDir() string {
+	return filepath.Join(c.Dir, InputDir)
+}
+
+func (c Case) ExpectedDir() string {
+	return filepath.Join(c.Dir, ExpectedDir)
+}
+
+// Config 用例的配置: input 或用例目录中的 goannotations.yaml，不向上查找，上级目录中的配置不影响用例
+func (c Case) Config() (config.Config, error) {
+	return config.LoadWithin(c.InputDir(), c.Dir)
+}
+
+// Cases 查找 root 下所有的用例，按生成器名、用例名排序
+func Cases(root string) ([]Case, error) {
+	generators, err := os.ReadDir(root)
+	if err != nil {
+		return nil, err
+	}
+	var cases []Case
+	for _, g := range generators {
+		if !g.IsDir() {
+			continue
+		}
+		entries, err := os.ReadDir(filepath.Join(root, g.Name()))
+		if err != nil {
+			return nil, err
+		}
+		for _, e := range entries {
+			dir := filepath.Join(root, g.Name(), e.Name())
+			if fi, err := os.Stat(filepath.Join(dir, InputDir)); e.IsDir() && err == nil && fi.IsDir() {
+				cases = append(cases, Case{Generator: g.Name(), Name: e.Name(), Dir: dir})
+			}
+		}
+	}
+	return cases, nil
+}
+
+// Result 一个用例的比较结果
+type Result struct {
+	Case    Case
+	Diffs   []string // expected 与生成结果不同的文件的 unified diff
+	Updated []string // update 时写入的文件
+}
+
+func (r Result) OK() bool {
+	return len(r.Diffs) == 0
+}
+
+// Check 运行生成器并与 expected 比较，update 为 true 时改为重写 expected
+func Check(c Case, g generator.Generator, update bool) (Result, error) {
+	result := Result{Case: c}
+	got, err := generate(c, g)
+	if err != nil {
+		return result, err
+	}
+	if update {
+		if err = os.RemoveAll(c.ExpectedDir()); err != nil {
+			return result, err
+		}
+		for _, rel := range sortedKeys(got) {
+			filename := filepath.Join(c.ExpectedDir(), filepath.FromSlash(rel))
+			if err = os.MkdirAll(filepath.Dir(filename), 0755); err != nil {
+				return result, err
+			}
+			if err = os.WriteFile(filename, got[rel], 0644); err != nil {
+				return result, err
+			}
+			result.Updated = append(result.Updated, filename)
+		}
+		return result, nil
+	}
+
+	want, err := readExpected(c.ExpectedDir())
+	if err != nil {
+		return result, err
+	}
+	names := sortedKeys(got)
+	for _, rel := range sortedKeys(want) {
+		if _, ok := got[rel]; !ok {
+			names = append(names, rel)
+		}
+	}
+	sort.Strings(names)
+	for _, rel := range names {
+		expected := filepath.ToSlash(filepath.Join(c.String(), ExpectedDir, rel))
+		if d := generator.UnifiedDiff(expected, rel+" (generated)", want[rel], got[rel]); d != "" {
+			result.Diffs = append(result.Diffs, d)
+		}
+	}
+	return result, nil
+}
+
+// generate 与 generate 命令相同地解析、生成和格式化，返回相对于 input 的路径到内容
+func generate(c Case, g generator.Generator) (map[string][]byte, error) {
+	inputDir := c.InputDir()
+	parsedSources, err := parser.ParseSourceDir(inputDir, "^.*.go$", generator.GenfileExcludePattern)
+	if err != nil {
+		return nil, fmt.Errorf("Error parsing %s: %s", inputDir, err)
+	}
+	files, err := g.Generate(inputDir, parsedSources)
+	if err != nil { // 生成失败的用例比较错误信息，位置中的文件名相对于 input
+		var errs generator.ErrorList
+		errs.Add(err)
+		errs.Sort()
+		var sb strings.Builder
+		for _, e := range errs {
+			sb.WriteString(strings.ReplaceAll(e.Error(), inputDir+string(filepath.Separator), "") + "\n")
+		}
+		return map[string][]byte{ErrorsFile: []byte(sb.String())}, nil
+	}
+	writer := generator.NewWriter()
+	if err = writer.Add(c.Generator, files); err != nil {
+		return nil, err
+	}
+	got := map[string][]byte{}
+	absInput, err := filepath.Abs(inputDir) // 配置中的输出路径相对于配置文件，是绝对路径
+	if err != nil {
+		return nil, err
+	}
+	for _, f := range writer.Files() {
+		path, err := filepath.Abs(f.Path)
+		if err != nil {
+			return nil, err
+		}
+		rel, err := filepath.Rel(absInput, path)
+		if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			return nil, fmt.Errorf("%s is outside of %s", f.Path, inputDir)
+		}
+		got[filepath.ToSlash(rel)] = generator.WithoutVersion(f.Content) // 避免 golden 文件随版本变化
+	}
+	return got, nil
+}
+
+func readExpected(dir string) (map[string][]byte, error) {
+	want := map[string][]byte{}
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		b, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		rel, _ := filepath.Rel(dir, path)
+		want[filepath.ToSlash(rel)] = bytes.ReplaceAll(b, []byte("\r\n"), []byte("\n"))
+		return nil
+	})
+	if errors.Is(err, fs.ErrNotExist) { // 没有 expected 表示不生成任何文件
+		return want, nil
+	}
+	return want, err
+}
+
+func sortedKeys(m map[string][]byte) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// TB testing.TB 中用到的方法，插件可以在自己的 _test.go 中直接传入 *testing.T
+type TB interface {
+	Helper()
+	Errorf(format string, args ...any)
+	Fatalf(format string, args ...any)
+}
+
+// Run 运行 root 下所有属于 name 的用例，newGenerator 为每个用例创建生成器
+func Run(t TB, root, name string, newGenerator func(c Case) (generator.Generator, error), update bool) {
+	t.Helper()
+	cases, err := Cases(root)
+	if err != nil {
+		t.Fatalf("Error reading %s: %s", root, err)
+		return
+	}
+	for _, c := range cases {
+		if c.Generator != name {
+			continue
+		}
+		g, err := newGenerator(c)
+		if err != nil {
+			t.Errorf("%s: %s", c, err)
+			continue
+		}
+		r, err := Check(c, g, update)
+		if err != nil {
+			t.Errorf("%s: %s", c, err)
+			continue
+		}
+		for _, d := range r.Diffs {
+			t.Errorf("%s: generated output differs from golden file (rerun with -update to accept)\n%s", c, d)
+		}
+	}
+}
@@ -0,0 +1,80 @@
+package generator
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"runtime/debug"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// Version 写入生成文件头的工具版本，
+// 可用 -ldflags "-X github.com/bwb0101/goAnnotations/generator.Version=v1.0.0" 指定，为空时取 go install 时的模块版本
+var Version = ""
+
+const toolName = "goAnnotations"
+
+// generatedRegex Go 约定的生成代码标记，见 https://go.dev/s/generatedcode
+var generatedRegex = regexp.MustCompile(`^// Code generated .* DO NOT EDIT\.$`)
+
+func ToolVersion() string {
+	if Version != "" {
+		return Version
+	}
+	// 本地修改过的构建(+dirty)每次都不同，视为 devel，避免 -check 误报
+	if bi, ok := debug.ReadBuildInfo(); ok && bi.Main.Version != "" && bi.Main.Version != "(devel)" && !strings.HasSuffix(bi.Main.Version, "+dirty") {
+		return bi.Main.Version
+	}
+	return "devel"
+}
+
+// Header 生成文件头: 标准的生成代码标记，以及工具版本、生成器和源文件。
+// 不记录源文件内容的 hash: 修改源文件中与生成无关的部分不应使生成的文件过时
+func Header(generatorName string, f File) string {
+	sources := relativeSources(f)
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "// Code generated by %s %s. DO NOT EDIT.\n", toolName, ToolVersion())
+	if generatorName != "" {
+		fmt.Fprintf(&sb, "%s%s\n", generatorHeaderPrefix, generatorName)
+	}
+	if len(sources) > 0 {
+		fmt.Fprintf(&sb, "// Sources: %s\n", strings.Join(sources, ", "))
+	}
+	return sb.String()
+}
+
+// generatorHeaderPrefix 文件头中记录生成器名的行，清理过时的文件时只处理这次运行了的生成器的文件
+const generatorHeaderPrefix = "// Generator: "
+
+var versionRegex = regexp.MustCompile(`^// Code generated by ` + toolName + ` \S+\. DO NOT EDIT\.\n`)
+
+// WithoutVersion 去掉文件头中的工具版本，不同的构建生成的相同内容比较时视为相同
+func WithoutVersion(content []byte) []byte {
+	return versionRegex.ReplaceAll(content, []byte("// Code generated by "+toolName+". DO NOT EDIT.\n"))
+}
+
+// withHeader 在内容前加上文件头，内容中原有的生成代码标记行会被替换
+func withHeader(generatorName string, f File) []byte {
+	content := f.Content
+	if first, rest, _ := strings.Cut(string(content), "\n"); generatedRegex.MatchString(strings.TrimSpace(first)) {
+		content = []byte(rest)
+	}
+	return append([]byte(Header(generatorName, f)+"\n"), content...)
+}
+
+// relativeSources 源文件相对于生成文件所在目录的路径，去重排序，避免文件头中出现本机路径
+func relativeSources(f File) []string {
+	var sources []string
+	for _, src := range f.Sources {
+		if rel, err := filepath.Rel(filepath.Dir(f.Path), src); err == nil {
+			src = rel
+		}
+		if src = filepath.ToSlash(src); !slices.Contains(sources, src) {
+			sources = append(sources, src)
+		}
+	}
+	sort.Strings(sources)
+	return sources
+}
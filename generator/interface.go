@@ -1,14 +1,44 @@
 package generator
 
 import (
+	"path/filepath"
+	"slices"
+
 	"github.com/bwb0101/goAnnotations/model"
 )
 
 const (
 	GenfilePrefix       = "gen_"
 	GenfileExcludeRegex = GenfilePrefix + ".*"
+	// GenfileExcludePattern 解析源码时排除生成文件的正则
+	GenfileExcludePattern = "^" + GenfilePrefix + ".*.go$"
+	legacyGenfileHeader   = "// 由注解自动生成: 不要手动编辑" // 旧版本生成的文件头
 )
 
+// File 生成器产出的一个文件，由 Writer 统一格式化并写入
+type File struct {
+	Path    string   // 目标文件路径
+	Content []byte   // 未格式化的内容，.go 文件写入前会经过 go/format
+	Sources []string // 生成该文件所依据的源文件
+}
+
+// WithSource 只保留 Sources 中包括 filename 的文件；filename 不带目录时只比较文件名
+func WithSource(files []File, filename string) []File {
+	match := func(f string) bool {
+		if filepath.Base(filename) == filename {
+			return filepath.Base(f) == filename
+		}
+		return filepath.Clean(f) == filepath.Clean(filename)
+	}
+	var filtered []File
+	for _, f := range files {
+		if slices.ContainsFunc(f.Sources, match) {
+			filtered = append(filtered, f)
+		}
+	}
+	return filtered
+}
+
 type Generator interface {
-	Generate(inputDir string, parsedSources model.ParsedSources) error
+	Generate(inputDir string, parsedSources model.ParsedSources) ([]File, error)
 }
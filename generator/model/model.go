@@ -9,9 +9,8 @@ package model
 
 import (
 	"fmt"
-	"go/format"
-	"os"
 	"path"
+	"slices"
 	"strings"
 	"sync"
 
@@ -19,20 +18,30 @@ import (
 	"github.com/bwb0101/goAnnotations/model"
 )
 
+// Options model 生成器的配置，生成的代码中分别以 framework_lib、storage 作为包名引用
+type Options struct {
+	Package            string // 生成文件的包名，ParsedSources.PkgName 不为空时优先
+	FrameworkLibImport string
+	StorageImport      string
+	Output             string // 输出文件，为空时为 <输入目录>/columns.go
+}
+
 type GeneratorModel struct {
 	targetFilename string
+	options        Options
 }
 
-func NewGeneratorModel() generator.Generator {
-	return &GeneratorModel{}
+func NewGeneratorModel(options Options) generator.Generator {
+	return &GeneratorModel{options: options}
 }
 
-func (eg *GeneratorModel) Generate(inputDir string, parsedSources model.ParsedSources) (err error) {
-	pkgName := "model_user"
+func (eg *GeneratorModel) Generate(inputDir string, parsedSources model.ParsedSources) ([]generator.File, error) {
+	pkgName := eg.options.Package
 	if parsedSources.PkgName != "" {
 		pkgName = parsedSources.PkgName
 	}
 	var pkgOnce sync.Once
+	var sources []string
 	var sb strings.Builder
 	var col_tb_sb strings.Builder
 	var col_st_sb strings.Builder
@@ -40,7 +49,6 @@ func (eg *GeneratorModel) Generate(inputDir string, parsedSources model.ParsedSo
 	var dao_type_sb strings.Builder
 	var dao_sb strings.Builder
 	var init_sb strings.Builder
-	sb.WriteString("// Code generated by goAnnotations. DO NOT EDIT.\n")
 	col_st_sb.WriteString("type colStruct struct {\n")
 	columns_sb.WriteString("var Columns = colStruct{\n")
 	dao_sb.WriteString("var DAO = struct {\n")
@@ -52,14 +60,17 @@ func (eg *GeneratorModel) Generate(inputDir string, parsedSources model.ParsedSo
 			pkgOnce.Do(func() {
 				sb.WriteString(fmt.Sprintf("package %s\n", pkgName))
 				sb.WriteString("import (\n")
-				sb.WriteString("\"common/framework_lib\"\n")
-				sb.WriteString("\"common/framework_lib/storage\"\n")
+				sb.WriteString(fmt.Sprintf("framework_lib \"%s\"\n", eg.options.FrameworkLibImport))
+				sb.WriteString(fmt.Sprintf("storage \"%s\"\n", eg.options.StorageImport))
 				sb.WriteString(")\n")
 				sb.WriteString("type tb_key struct {\n")
 				sb.WriteString("TKey string\n")
 				sb.WriteString("MKey string\n")
 				sb.WriteString("}\n")
 			})
+			if !slices.Contains(sources, st.Filename) {
+				sources = append(sources, st.Filename)
+			}
 			col_tb_sb.WriteString(fmt.Sprintf("type col_%s struct {\n", st.Name))
 			col_tb_sb.WriteString("tb_key\n")
 			col_tb_sb.WriteString("TableName  storage.ColumnTblName // 表名\n")
@@ -91,11 +102,17 @@ func (eg *GeneratorModel) Generate(inputDir string, parsedSources model.ParsedSo
 	dao_sb.WriteString("}{}\n")
 	init_sb.WriteString("})\n")
 	init_sb.WriteString("}\n")
+	if len(sources) == 0 { // 没有表结构时不生成
+		return nil, nil
+	}
 	//
-	if bs, e := format.Source([]byte(sb.String() + col_tb_sb.String() + col_st_sb.String() + columns_sb.String() + dao_type_sb.String() + dao_sb.String() + init_sb.String())); e != nil {
-		err = e
-	} else {
-		err = os.WriteFile(path.Join(inputDir, "columns.go"), bs, 0644)
+	output := eg.options.Output
+	if output == "" {
+		output = path.Join(inputDir, "columns.go")
 	}
-	return
+	return []generator.File{{
+		Path:    output,
+		Content: []byte(sb.String() + col_tb_sb.String() + col_st_sb.String() + columns_sb.String() + dao_type_sb.String() + dao_sb.String() + init_sb.String()),
+		Sources: sources,
+	}}, nil
 }
@@ -0,0 +1,273 @@
+// Package openapi 根据 net="http" 的 @Handler 生成 OpenAPI 3 文档。
+//
+// 请求体的 schema 来自 dataPtrStruct 指定的 struct(在解析到的源码中查找)，
+// struct 在子目录的包中时需要配置 openapi.recursive: true(只用于查找 struct)，否则找不到，只生成带 x-go-type 的 object；
+// validation="token" 生成为 header 中的 apiKey，说明取自函数的文档注释。
+package openapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/bwb0101/goAnnotations/annotation"
+	"github.com/bwb0101/goAnnotations/generator"
+	"github.com/bwb0101/goAnnotations/generator/api"
+	"github.com/bwb0101/goAnnotations/model"
+	"github.com/bwb0101/goAnnotations/parser"
+)
+
+const tokenScheme = "token"
+
+// Options openapi 生成器的配置
+type Options struct {
+	Output      string // 输出文件，为空时为 <输入目录>/openapi.json；.yaml/.yml 输出 YAML
+	Title       string // 默认 API
+	Version     string // 默认 1.0.0
+	TokenHeader string // validation="token" 时 token 所在的 header，默认 token
+	Recursive   bool   // 在 inputDir 及其子目录中查找 dataPtrStruct 引用的 struct
+}
+
+type GeneratorOpenApi struct {
+	options Options
+}
+
+func NewGeneratorOpenApi(options Options) generator.Generator {
+	return &GeneratorOpenApi{options: options}
+}
+
+func (g *GeneratorOpenApi) Generate(inputDir string, parsedSources model.ParsedSources) ([]generator.File, error) {
+	all, err := api.ExtractHandlers(parsedSources.Operations)
+	if err != nil {
+		return nil, err
+	}
+	var handlers []api.Handler
+	for _, h := range all {
+		if h.Net == "http" && h.Path != "" {
+			handlers = append(handlers, h)
+		}
+	}
+	if len(handlers) == 0 {
+		return nil, nil
+	}
+	doc := document{
+		OpenApi: "3.0.3",
+		Info:    info{Title: or(g.options.Title, "API"), Version: or(g.options.Version, "1.0.0")},
+		Paths:   map[string]pathItem{},
+	}
+	structs := parsedSources.Structs
+	if g.options.Recursive {
+		tree, err := parser.ParseSourceTree(inputDir, "^.*.go$", generator.GenfileExcludePattern)
+		if err != nil {
+			return nil, fmt.Errorf("Error parsing %s: %s", inputDir, err)
+		}
+		structs = tree.Structs
+	}
+	s := &schemas{structs: structs, defs: map[string]schema{}}
+	var sources []string
+	for _, h := range handlers {
+		op := g.operation(h, s)
+		methods := h.Methods()
+		if len(methods) == 0 { // 不限制 method 时按 post 记录
+			methods, op.AnyMethod = []string{"POST"}, true
+		}
+		item := doc.Paths[h.Path]
+		if item == nil {
+			item = pathItem{}
+			doc.Paths[h.Path] = item
+		}
+		for _, m := range methods {
+			if _, ok := item[strings.ToLower(m)]; !ok { // 重复的路由由 api 生成器报告
+				item[strings.ToLower(m)] = op
+			}
+		}
+		if op.Security != nil {
+			doc.components().SecuritySchemes = map[string]securityScheme{
+				tokenScheme: {Type: "apiKey", In: "header", Name: or(g.options.TokenHeader, "token")},
+			}
+		}
+		sources = append(sources, h.Operation.Filename)
+	}
+	if len(s.defs) > 0 {
+		doc.components().Schemas = s.defs
+	}
+	sources = append(sources, s.sources...)
+
+	output := g.options.Output
+	if output == "" {
+		output = filepath.Join(inputDir, "openapi.json")
+	}
+	content, err := doc.encode(output)
+	if err != nil {
+		return nil, fmt.Errorf("Error encoding %s: %s", output, err)
+	}
+	return []generator.File{{Path: output, Content: content, Sources: sources}}, nil
+}
+
+func (g *GeneratorOpenApi) operation(h api.Handler, s *schemas) *operation {
+	summary, description := docText(h.Operation.DocLines)
+	summary = strings.TrimPrefix(summary, h.Operation.Name+" ") // Go 的注释以函数名开头
+	op := &operation{
+		OperationId: h.Key(),
+		Summary:     summary,
+		Description: description,
+		Tags:        []string{h.Operation.PackageName},
+		Responses:   map[string]response{"200": {Description: "OK"}},
+	}
+	for _, p := range h.PathParams() {
+		op.Parameters = append(op.Parameters, parameter{Name: p.Name, In: "path", Required: true, Schema: paramSchema(p.Type)})
+	}
+	if v := h.Args["dataPtrStruct"]; v != "" {
+		if importPath, typeName, err := api.SplitDataPtrStruct(v); err == nil {
+			body := &requestBody{Required: true, Content: map[string]mediaType{}}
+			if h.Args["bodyType"] == "1" { // framebody
+				body.Content["application/octet-stream"] = mediaType{Schema: schema{"type": "string", "format": "binary"}}
+			} else {
+				body.Content["application/json"] = mediaType{Schema: s.typeSchema(typeName, importPath, "", "")}
+			}
+			op.RequestBody = body
+		}
+	}
+	if n, _ := strconv.Atoi(h.Args["bodyLimit"]); n > 0 {
+		op.BodyLimit = n << 10
+	}
+	if h.Args["resp"] == "object" {
+		op.Responses["200"] = response{Description: "OK", Content: map[string]mediaType{"application/json": {Schema: schema{}}}}
+	}
+	if h.Args["validation"] == "token" {
+		op.Security = []map[string][]string{{tokenScheme: {}}}
+	}
+	return op
+}
+
+// docText 文档注释中去掉注解后的第一行和其余的行
+func docText(docLines []string) (summary, description string) {
+	var lines []string
+	for _, line := range docLines {
+		if _, ok := annotation.ParseLine(line); ok {
+			continue
+		}
+		if text := strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(line), "//")); text != "" || len(lines) > 0 {
+			lines = append(lines, text)
+		}
+	}
+	if len(lines) == 0 {
+		return "", ""
+	}
+	return lines[0], strings.TrimSpace(strings.Join(lines[1:], "\n"))
+}
+
+func paramSchema(typ string) schema {
+	switch typ {
+	case "int":
+		return schema{"type": "integer"}
+	case "int64":
+		return schema{"type": "integer", "format": "int64"}
+	case "uint64":
+		return schema{"type": "integer", "minimum": 0}
+	case "bool":
+		return schema{"type": "boolean"}
+	}
+	return schema{"type": "string"}
+}
+
+func or(s, def string) string {
+	if s == "" {
+		return def
+	}
+	return s
+}
+
+// --------------------------------------------------- 文档结构 ---------------------------------------------------
+
+type schema = map[string]any
+
+type document struct {
+	OpenApi    string              `json:"openapi" yaml:"openapi"`
+	Info       info                `json:"info" yaml:"info"`
+	Paths      map[string]pathItem `json:"paths" yaml:"paths"`
+	Components *components         `json:"components,omitempty" yaml:"components,omitempty"`
+}
+
+func (d *document) components() *components {
+	if d.Components == nil {
+		d.Components = &components{}
+	}
+	return d.Components
+}
+
+// encode 按文件扩展名输出 JSON 或 YAML
+func (d *document) encode(filename string) ([]byte, error) {
+	var buf bytes.Buffer
+	switch strings.ToLower(filepath.Ext(filename)) {
+	case ".yaml", ".yml":
+		enc := yaml.NewEncoder(&buf)
+		enc.SetIndent(2)
+		if err := enc.Encode(d); err != nil {
+			return nil, err
+		}
+		return buf.Bytes(), enc.Close()
+	}
+	enc := json.NewEncoder(&buf)
+	enc.SetIndent("", "  ")
+	enc.SetEscapeHTML(false)
+	err := enc.Encode(d)
+	return buf.Bytes(), err
+}
+
+type info struct {
+	Title   string `json:"title" yaml:"title"`
+	Version string `json:"version" yaml:"version"`
+}
+
+type pathItem map[string]*operation // 小写的 method ->
+
+type operation struct {
+	OperationId string                `json:"operationId" yaml:"operationId"`
+	Summary     string                `json:"summary,omitempty" yaml:"summary,omitempty"`
+	Description string                `json:"description,omitempty" yaml:"description,omitempty"`
+	Tags        []string              `json:"tags,omitempty" yaml:"tags,omitempty"`
+	Parameters  []parameter           `json:"parameters,omitempty" yaml:"parameters,omitempty"`
+	RequestBody *requestBody          `json:"requestBody,omitempty" yaml:"requestBody,omitempty"`
+	Responses   map[string]response   `json:"responses" yaml:"responses"`
+	Security    []map[string][]string `json:"security,omitempty" yaml:"security,omitempty"`
+	BodyLimit   int                   `json:"x-body-limit,omitempty" yaml:"x-body-limit,omitempty"` // 请求体的字节数上限
+	AnyMethod   bool                  `json:"x-any-method,omitempty" yaml:"x-any-method,omitempty"` // 注册时没有限制 method
+}
+
+type parameter struct {
+	Name     string `json:"name" yaml:"name"`
+	In       string `json:"in" yaml:"in"`
+	Required bool   `json:"required" yaml:"required"`
+	Schema   schema `json:"schema" yaml:"schema"`
+}
+
+type requestBody struct {
+	Required bool                 `json:"required" yaml:"required"`
+	Content  map[string]mediaType `json:"content" yaml:"content"`
+}
+
+type mediaType struct {
+	Schema schema `json:"schema" yaml:"schema"`
+}
+
+type response struct {
+	Description string               `json:"description" yaml:"description"`
+	Content     map[string]mediaType `json:"content,omitempty" yaml:"content,omitempty"`
+}
+
+type components struct {
+	Schemas         map[string]schema         `json:"schemas,omitempty" yaml:"schemas,omitempty"`
+	SecuritySchemes map[string]securityScheme `json:"securitySchemes,omitempty" yaml:"securitySchemes,omitempty"`
+}
+
+type securityScheme struct {
+	Type string `json:"type" yaml:"type"`
+	In   string `json:"in" yaml:"in"`
+	Name string `json:"name" yaml:"name"`
+}
@@ -0,0 +1,116 @@
+package openapi
+
+import (
+	"path/filepath"
+	"strings"
+
+	"github.com/bwb0101/goAnnotations/model"
+)
+
+// schemas 把 struct 转成 components/schemas 中的定义，名字为 pkg.Struct
+type schemas struct {
+	structs []model.Struct
+	defs    map[string]schema
+	sources []string // 用到的 struct 所在的文件
+}
+
+// typeSchema Go 类型的 schema；importPath 为类型所在包的 import 路径，
+// 同包的类型(没有 pkg. 前缀)用 pkg 和 dir 查找
+func (s *schemas) typeSchema(typeName, importPath, pkg, dir string) schema {
+	typeName = strings.TrimPrefix(typeName, "*")
+	f := model.Field{TypeName: typeName}
+	switch {
+	case typeName == "[]byte":
+		return schema{"type": "string", "format": "byte"}
+	case f.IsSlice():
+		return schema{"type": "array", "items": s.typeSchema(f.SliceElementTypeName(), importPath, pkg, dir)}
+	case f.IsMap():
+		_, value := f.SplitMapTypeNames()
+		return schema{"type": "object", "additionalProperties": s.typeSchema(value, importPath, pkg, dir)}
+	}
+	switch typeName {
+	case "string":
+		return schema{"type": "string"}
+	case "bool":
+		return schema{"type": "boolean"}
+	case "int", "int8", "int16", "int32", "uint", "uint8", "uint16", "uint32":
+		return schema{"type": "integer"}
+	case "int64", "uint64":
+		return schema{"type": "integer", "format": "int64"}
+	case "float32":
+		return schema{"type": "number", "format": "float"}
+	case "float64":
+		return schema{"type": "number", "format": "double"}
+	case "time.Time":
+		return schema{"type": "string", "format": "date-time"}
+	case "mydate.MyDate":
+		return schema{"type": "string", "format": "date"}
+	case "any", "interface{}":
+		return schema{}
+	}
+	if p, name, ok := strings.Cut(typeName, "."); ok {
+		pkg, dir = p, importPath
+		typeName = name
+	}
+	if st := model.FindStruct(s.structs, pkg, typeName, dir); st != nil {
+		return schema{"$ref": "#/components/schemas/" + s.define(st)}
+	}
+	// 不在解析的源码中
+	return schema{"type": "object", "x-go-type": strings.TrimPrefix(pkg+"."+typeName, ".")}
+}
+
+// define 把 st 加到 defs 中，返回名字
+func (s *schemas) define(st *model.Struct) string {
+	name := st.PackageName + "." + st.Name
+	if _, ok := s.defs[name]; ok {
+		return name
+	}
+	def := schema{"type": "object"}
+	s.defs[name] = def // 先占位，struct 可能引用自己
+	s.sources = append(s.sources, st.Filename)
+	properties := schema{}
+	s.addProperties(properties, st, map[*model.Struct]bool{})
+	if len(properties) > 0 {
+		def["properties"] = properties
+	}
+	if summary, description := docText(st.DocLines); summary != "" {
+		def["description"] = strings.TrimSpace(summary + "\n" + description)
+	}
+	return name
+}
+
+// addProperties 按 encoding/json 的规则: 嵌入的 struct 展开，json:"-" 和未导出的字段忽略；
+// visited 为已经展开的 struct，互相嵌入时只展开一次
+func (s *schemas) addProperties(properties schema, st *model.Struct, visited map[*model.Struct]bool) {
+	visited[st] = true
+	dir := filepath.Dir(st.Filename)
+	for _, f := range st.Fields {
+		name, embedded := f.JsonName()
+		if embedded {
+			typeName := f.DereferencedTypeName()
+			pkg, hint := st.PackageName, dir
+			if p, n, ok := strings.Cut(typeName, "."); ok {
+				pkg, typeName, hint = p, n, f.PackageName
+			}
+			if embedded := model.FindStruct(s.structs, pkg, typeName, hint); embedded != nil && !visited[embedded] {
+				s.sources = append(s.sources, embedded.Filename)
+				s.addProperties(properties, embedded, visited)
+			}
+			continue
+		}
+		if name == "" {
+			continue
+		}
+		property := s.typeSchema(f.TypeName, f.PackageName, st.PackageName, dir)
+		if _, ok := property["$ref"]; !ok { // OpenAPI 3.0 中 $ref 旁边的字段会被忽略
+			text := f.CommentLines
+			if len(f.DocLines) > 0 {
+				text = f.DocLines
+			}
+			if summary, description := docText(text); summary != "" {
+				property["description"] = strings.TrimSpace(summary + "\n" + description)
+			}
+		}
+		properties[name] = property
+	}
+}
@@ -0,0 +1,95 @@
+package generator
+
+import (
+	"bufio"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// IsOwned 判断文件是否由本工具生成: 文件名以 gen_ 开头，并且第一行是生成代码的注释
+func IsOwned(filename string) (bool, error) {
+	if !strings.HasPrefix(filepath.Base(filename), GenfilePrefix) {
+		return false, nil
+	}
+	fp, err := os.Open(filename)
+	if err != nil {
+		return false, err
+	}
+	defer fp.Close()
+	scanner := bufio.NewScanner(fp)
+	if !scanner.Scan() {
+		return false, scanner.Err()
+	}
+	first := strings.TrimSpace(scanner.Text())
+	return first == legacyGenfileHeader || (generatedRegex.MatchString(first) && strings.HasPrefix(first, "// Code generated by "+toolName+" ")), nil
+}
+
+// OwnerGenerator 文件头中记录的生成器名，没有记录时为空
+func OwnerGenerator(filename string) (string, error) {
+	fp, err := os.Open(filename)
+	if err != nil {
+		return "", err
+	}
+	defer fp.Close()
+	scanner := bufio.NewScanner(fp)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if !strings.HasPrefix(line, "//") { // 文件头只到第一行非注释
+			break
+		}
+		if name, ok := strings.CutPrefix(line, generatorHeaderPrefix); ok {
+			return strings.TrimSpace(name), nil
+		}
+	}
+	return "", scanner.Err()
+}
+
+// OwnedDirs root 以及所有子目录，与 parser.ParseSourceTree 一样跳过 vendor、testdata 和隐藏目录
+func OwnedDirs(root string) ([]string, error) {
+	var dirs []string
+	err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || !d.IsDir() {
+			return err
+		}
+		if name := d.Name(); path != root && (name == "vendor" || name == "testdata" || strings.HasPrefix(name, ".")) {
+			return filepath.SkipDir
+		}
+		dirs = append(dirs, path)
+		return nil
+	})
+	return dirs, err
+}
+
+// FindOwned 列出 dirs(不递归)中由本工具生成的文件
+func FindOwned(dirs []string) ([]string, error) {
+	seen := map[string]bool{}
+	var owned []string
+	for _, dir := range dirs {
+		dir = filepath.Clean(dir)
+		if seen[dir] {
+			continue
+		}
+		seen[dir] = true
+		filenames, err := filepath.Glob(filepath.Join(dir, GenfilePrefix+"*"))
+		if err != nil {
+			return nil, err
+		}
+		for _, filename := range filenames {
+			if fi, err := os.Stat(filename); err != nil || fi.IsDir() {
+				continue
+			}
+			ok, err := IsOwned(filename)
+			if err != nil {
+				return nil, err
+			}
+			if ok {
+				owned = append(owned, filename)
+			}
+		}
+	}
+	sort.Strings(owned)
+	return owned, nil
+}
@@ -0,0 +1,142 @@
+// Package plugin 运行进程外的生成器插件。
+//
+// 插件是名为 goannotations-gen-<name> 的可执行文件(或在配置中指定路径)，
+// 通过 stdin 接收 JSON 格式的 Request，通过 stdout 返回 JSON 格式的 Response；
+// stderr 原样输出，退出码非 0 视为失败。
+package plugin
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/bwb0101/goAnnotations/generator"
+	"github.com/bwb0101/goAnnotations/logger"
+	"github.com/bwb0101/goAnnotations/model"
+)
+
+const ExecutablePrefix = "goannotations-gen-"
+
+type Request struct {
+	Name          string              `json:"name"`
+	InputDir      string              `json:"inputDir"`
+	PkgName       string              `json:"pkgName,omitempty"`
+	Options       map[string]string   `json:"options,omitempty"`
+	ParsedSources model.ParsedSources `json:"parsedSources"`
+}
+
+type Response struct {
+	Files       []File       `json:"files,omitempty"`
+	Diagnostics []Diagnostic `json:"diagnostics,omitempty"`
+}
+
+// File 要写入的文件，Path 相对于 InputDir
+type File struct {
+	Path    string   `json:"path"`
+	Content string   `json:"content"`
+	Sources []string `json:"sources,omitempty"` // 生成该文件所依据的源文件
+}
+
+const (
+	SeverityError   = "error"
+	SeverityWarning = "warning"
+	SeverityInfo    = "info"
+)
+
+type Diagnostic struct {
+	Severity string `json:"severity"`
+	Message  string `json:"message"`
+	Filename string `json:"filename,omitempty"`
+	Line     int    `json:"line,omitempty"`
+	Column   int    `json:"column,omitempty"`
+}
+
+func (d Diagnostic) String() string {
+	pos := d.Filename
+	if pos != "" && d.Line > 0 {
+		pos = fmt.Sprintf("%s:%d", pos, d.Line)
+	}
+	if pos != "" {
+		return fmt.Sprintf("%s: %s: %s", pos, d.Severity, d.Message)
+	}
+	return fmt.Sprintf("%s: %s", d.Severity, d.Message)
+}
+
+type GeneratorPlugin struct {
+	name    string
+	path    string
+	options map[string]string
+}
+
+func NewGeneratorPlugin(name, path string, options map[string]string) generator.Generator {
+	return &GeneratorPlugin{name: name, path: path, options: options}
+}
+
+// Lookup 在 PATH 中查找名为 goannotations-gen-<name> 的插件
+func Lookup(name string) (string, error) {
+	return exec.LookPath(ExecutablePrefix + name)
+}
+
+func (pg *GeneratorPlugin) Generate(inputDir string, parsedSources model.ParsedSources) ([]generator.File, error) {
+	resp, err := pg.run(Request{
+		Name:          pg.name,
+		InputDir:      inputDir,
+		PkgName:       parsedSources.PkgName,
+		Options:       pg.options,
+		ParsedSources: parsedSources,
+	})
+	if err != nil {
+		return nil, err
+	}
+	// error 级别的诊断和其他生成器的错误一起报告
+	var errs generator.ErrorList
+	for _, d := range resp.Diagnostics {
+		if d.Severity == SeverityError {
+			errs.Add(generator.Errorf(generator.Position{Filename: d.Filename, Line: d.Line, Col: d.Column}, "%s", d.Message))
+		} else {
+			logger.Infof("%s: %s", pg.name, d)
+		}
+	}
+	if err = errs.Err(); err != nil {
+		return nil, err
+	}
+	files := make([]generator.File, 0, len(resp.Files))
+	for _, f := range resp.Files {
+		if f.Path == "" || filepath.IsAbs(f.Path) {
+			return nil, fmt.Errorf("plugin %s: invalid file path %q: must be relative to the input dir", pg.name, f.Path)
+		}
+		if p := filepath.Clean(f.Path); p == ".." || strings.HasPrefix(p, ".."+string(filepath.Separator)) {
+			return nil, fmt.Errorf("plugin %s: invalid file path %q: outside the input dir", pg.name, f.Path)
+		}
+		files = append(files, generator.File{
+			Path:    filepath.Join(inputDir, f.Path),
+			Content: []byte(f.Content),
+			Sources: f.Sources,
+		})
+	}
+	return files, nil
+}
+
+func (pg *GeneratorPlugin) run(req Request) (Response, error) {
+	in, err := json.Marshal(req)
+	if err != nil {
+		return Response{}, err
+	}
+	var out bytes.Buffer
+	cmd := exec.Command(pg.path)
+	cmd.Stdin = bytes.NewReader(in)
+	cmd.Stdout = &out
+	cmd.Stderr = os.Stderr
+	if err = cmd.Run(); err != nil {
+		return Response{}, fmt.Errorf("Error running plugin %s (%s): %s", pg.name, pg.path, err)
+	}
+	resp := Response{}
+	if err = json.Unmarshal(out.Bytes(), &resp); err != nil {
+		return Response{}, fmt.Errorf("Error decoding response of plugin %s: %s", pg.name, err)
+	}
+	return resp, nil
+}
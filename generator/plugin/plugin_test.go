@@ -0,0 +1,100 @@
+package plugin
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/bwb0101/goAnnotations/generator"
+	"github.com/bwb0101/goAnnotations/model"
+)
+
+// pluginEnv 设置时测试程序本身作为插件运行，值为要返回的 Response 的类型
+const pluginEnv = "GOANNOTATIONS_TEST_PLUGIN"
+
+func TestMain(m *testing.M) {
+	if mode := os.Getenv(pluginEnv); mode != "" {
+		os.Exit(runTestPlugin(mode))
+	}
+	os.Exit(m.Run())
+}
+
+// runTestPlugin 读取 Request，按 mode 返回 Response
+func runTestPlugin(mode string) int {
+	var req Request
+	if err := json.NewDecoder(os.Stdin).Decode(&req); err != nil {
+		os.Stderr.WriteString(err.Error())
+		return 1
+	}
+	var resp Response
+	switch mode {
+	case "echo":
+		resp.Files = []File{{
+			Path:    "gen_" + req.Name + ".go",
+			Content: "package " + req.PkgName + "\n\nconst Greeting = \"" + req.Options["greeting"] + "\"\n",
+			Sources: []string{filepath.Join(req.InputDir, "a.go")},
+		}}
+		resp.Diagnostics = []Diagnostic{{Severity: SeverityInfo, Message: "done"}}
+	case "error":
+		resp.Diagnostics = []Diagnostic{{Severity: SeverityError, Message: "bad annotation", Filename: "a.go", Line: 3}}
+	case "outside":
+		resp.Files = []File{{Path: "../gen_x.go"}}
+	case "exit":
+		return 3
+	}
+	if err := json.NewEncoder(os.Stdout).Encode(resp); err != nil {
+		return 1
+	}
+	return 0
+}
+
+func generate(t *testing.T, mode string) ([]generator.File, error) {
+	t.Helper()
+	t.Setenv(pluginEnv, mode)
+	g := NewGeneratorPlugin("echo", os.Args[0], map[string]string{"greeting": "hi"})
+	return g.Generate("input", model.ParsedSources{PkgName: "p"})
+}
+
+func TestGenerate(t *testing.T) {
+	files, err := generate(t, "echo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) != 1 {
+		t.Fatalf("got %d files, want 1", len(files))
+	}
+	f := files[0]
+	if want := filepath.Join("input", "gen_echo.go"); f.Path != want {
+		t.Errorf("Path = %q, want %q", f.Path, want)
+	}
+	if want := "package p\n\nconst Greeting = \"hi\"\n"; string(f.Content) != want {
+		t.Errorf("Content = %q, want %q", f.Content, want)
+	}
+	if want := filepath.Join("input", "a.go"); len(f.Sources) != 1 || f.Sources[0] != want {
+		t.Errorf("Sources = %q, want [%q]", f.Sources, want)
+	}
+}
+
+func TestGenerateErrors(t *testing.T) {
+	tests := []struct {
+		mode string
+		want string
+	}{
+		{mode: "error", want: "a.go:3"},
+		{mode: "outside", want: "outside the input dir"},
+		{mode: "exit", want: "exit status 3"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.mode, func(t *testing.T) {
+			_, err := generate(t, tt.mode)
+			if err == nil {
+				t.Fatal("expected error")
+			}
+			if !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("error %q does not contain %q", err, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,114 @@
+package generator
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/bwb0101/goAnnotations/config"
+)
+
+// Entry 注册到 Registry 的生成器
+type Entry struct {
+	Name      string
+	New       func(cfg config.Config) Generator // 按本次运行的配置创建生成器
+	DependsOn []string                          // 依赖的生成器: 选中本生成器时一并运行，并且先于本生成器运行
+	Optional  bool                              // 默认不运行，需要选中或在配置中打开
+}
+
+// Registry 按名字管理生成器，运行顺序稳定: 先满足依赖，其余按注册顺序
+type Registry struct {
+	entries []Entry
+	index   map[string]int
+}
+
+func NewRegistry() *Registry {
+	return &Registry{index: map[string]int{}}
+}
+
+func (r *Registry) Register(e Entry) error {
+	if e.Name == "" || e.New == nil {
+		return fmt.Errorf("generator must have a name and a constructor")
+	}
+	if _, ok := r.index[e.Name]; ok {
+		return fmt.Errorf("generator %s already registered", e.Name)
+	}
+	r.index[e.Name] = len(r.entries)
+	r.entries = append(r.entries, e)
+	return nil
+}
+
+// Lookup 按名字查找已注册的生成器
+func (r *Registry) Lookup(name string) (Entry, bool) {
+	i, ok := r.index[name]
+	if !ok {
+		return Entry{}, false
+	}
+	return r.entries[i], true
+}
+
+func (r *Registry) Names() []string {
+	names := make([]string, 0, len(r.entries))
+	for _, e := range r.entries {
+		names = append(names, e.Name)
+	}
+	return names
+}
+
+// Resolve 返回要运行的生成器(已排好序)。
+// selected 为空时运行配置中打开的生成器: enabled 中没有的按 !Optional；否则只运行 selected 及其依赖，enabled 不再生效
+func (r *Registry) Resolve(selected []string, enabled map[string]bool) ([]Entry, error) {
+	if len(selected) == 0 {
+		for _, e := range r.entries {
+			if on, ok := enabled[e.Name]; on || (!ok && !e.Optional) {
+				selected = append(selected, e.Name)
+			}
+		}
+	}
+	for _, name := range selected {
+		if _, ok := r.index[name]; !ok {
+			return nil, fmt.Errorf("unknown generator %s (available: %s)", name, strings.Join(r.Names(), ","))
+		}
+	}
+
+	const (
+		visiting = 1
+		done     = 2
+	)
+	state := map[string]int{}
+	var ordered []Entry
+	var visit func(name string, path []string) error
+	visit = func(name string, path []string) error {
+		switch state[name] {
+		case done:
+			return nil
+		case visiting:
+			return fmt.Errorf("generator dependency cycle: %s", strings.Join(append(path, name), " -> "))
+		}
+		idx, ok := r.index[name]
+		if !ok {
+			return fmt.Errorf("generator %s depends on unknown generator %s", path[len(path)-1], name)
+		}
+		state[name] = visiting
+		for _, dep := range r.entries[idx].DependsOn {
+			if err := visit(dep, append(path, name)); err != nil {
+				return err
+			}
+		}
+		state[name] = done
+		ordered = append(ordered, r.entries[idx])
+		return nil
+	}
+	// 按注册顺序遍历，保证结果稳定
+	wanted := map[string]bool{}
+	for _, name := range selected {
+		wanted[name] = true
+	}
+	for _, e := range r.entries {
+		if wanted[e.Name] {
+			if err := visit(e.Name, nil); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return ordered, nil
+}
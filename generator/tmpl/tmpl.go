@@ -0,0 +1,298 @@
+// Package tmpl 从项目目录加载用户自定义的 text/template 生成器。
+//
+// 每个 *.tmpl 文件以 front-matter 开头，声明触发它的注解、作用范围和输出文件名:
+//
+//	---
+//	annotation: @Repository
+//	scope: struct
+//	output: gen_{{lowerFirst .Struct.Name}}_repository.go
+//	---
+//	package {{.PackageName}}
+//	...
+//
+// scope = struct: 每个带注解的 struct 生成一个文件，.Struct 为该 struct；
+// scope = package: 每个 package 生成一个文件，.Structs/.Operations/.Interfaces 为该 package 中带注解的元素。
+// output 同样是模板，生成的文件写到元素源文件所在的目录。
+package tmpl
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"text/template"
+
+	"github.com/bwb0101/goAnnotations/annotation"
+	"github.com/bwb0101/goAnnotations/generator"
+	"github.com/bwb0101/goAnnotations/generator/util"
+	"github.com/bwb0101/goAnnotations/model"
+)
+
+const (
+	DefaultDir = ".goannotations/templates"
+
+	ScopeStruct  = "struct"
+	ScopePackage = "package"
+)
+
+// TemplateData 传给用户模板的数据
+type TemplateData struct {
+	PackageName string
+	Annotation  string
+	Struct      *model.Struct // scope=struct
+	Structs     []model.Struct
+	Operations  []model.Operation
+	Interfaces  []model.Interface
+	Model       model.ParsedSources
+}
+
+type userTemplate struct {
+	filename   string
+	annotation string
+	scope      string
+	output     string
+	body       string
+}
+
+type GeneratorTemplate struct {
+	dir string
+}
+
+// NewGeneratorTemplate dir 为模板目录，相对路径时相对于 inputDir
+func NewGeneratorTemplate(dir string) generator.Generator {
+	if dir == "" {
+		dir = DefaultDir
+	}
+	return &GeneratorTemplate{dir: dir}
+}
+
+func (tg *GeneratorTemplate) Generate(inputDir string, parsedSources model.ParsedSources) ([]generator.File, error) {
+	dir := tg.dir
+	if !filepath.IsAbs(dir) {
+		dir = filepath.Join(inputDir, dir)
+	}
+	var errs generator.ErrorList
+	templates, err := loadTemplates(dir)
+	errs.Add(err)
+	var files []generator.File
+	for _, ut := range templates {
+		for _, data := range collect(ut, parsedSources) {
+			f, err := ut.generate(data)
+			if err != nil {
+				errs.Add(err)
+				continue
+			}
+			files = append(files, f)
+		}
+	}
+	return files, errs.Err()
+}
+
+func loadTemplates(dir string) ([]userTemplate, error) {
+	filenames, err := filepath.Glob(filepath.Join(dir, "*.tmpl"))
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(filenames)
+	var templates []userTemplate
+	var errs generator.ErrorList // 一个模版有错误时继续加载其他模版
+	for _, filename := range filenames {
+		ut, err := loadTemplate(filename)
+		if err != nil {
+			errs.Add(err)
+			continue
+		}
+		templates = append(templates, ut)
+	}
+	return templates, errs.Err()
+}
+
+func loadTemplate(filename string) (userTemplate, error) {
+	b, err := os.ReadFile(filename)
+	if err != nil {
+		return userTemplate{}, err
+	}
+	ut := userTemplate{filename: filename, scope: ScopeStruct}
+	lines := strings.SplitAfter(string(b), "\n")
+	if strings.TrimSpace(lines[0]) != "---" {
+		return ut, fmt.Errorf("%s: missing front-matter", filename)
+	}
+	offset := len(lines[0])
+	closed := false
+	for i := 1; i < len(lines) && !closed; i++ {
+		text := strings.TrimSpace(lines[i])
+		offset += len(lines[i])
+		if text == "---" {
+			closed = true
+			continue
+		}
+		if text == "" {
+			continue
+		}
+		k, v, ok := strings.Cut(text, ":")
+		if !ok {
+			return ut, fmt.Errorf("%s:%d: expected key: value", filename, i+1)
+		}
+		switch k, v = strings.TrimSpace(k), strings.TrimSpace(v); k {
+		case "annotation":
+			ut.annotation = v
+		case "scope":
+			ut.scope = v
+		case "output":
+			ut.output = v
+		default:
+			return ut, fmt.Errorf("%s:%d: unknown front-matter key %s", filename, i+1, k)
+		}
+	}
+	switch {
+	case !closed:
+		return ut, fmt.Errorf("%s: front-matter not closed by ---", filename)
+	case !strings.HasPrefix(ut.annotation, "@"):
+		return ut, fmt.Errorf("%s: annotation must start with @", filename)
+	case ut.scope != ScopeStruct && ut.scope != ScopePackage:
+		return ut, fmt.Errorf("%s: scope must be %s or %s", filename, ScopeStruct, ScopePackage)
+	case ut.output == "":
+		return ut, fmt.Errorf("%s: missing output", filename)
+	}
+	ut.body = string(b[offset:])
+	return ut, nil
+}
+
+// collect 按作用范围收集带注解的元素，scope=package 时按源文件目录+包名合并
+func collect(ut userTemplate, parsedSources model.ParsedSources) []TemplateData {
+	var datas []TemplateData
+	packages := map[string]*TemplateData{}
+	var order []string
+	pkgData := func(pkgName, filename string) *TemplateData {
+		key := filepath.Dir(filename) + "|" + pkgName
+		if packages[key] == nil {
+			packages[key] = &TemplateData{PackageName: pkgName, Annotation: ut.annotation, Model: parsedSources}
+			order = append(order, key)
+		}
+		return packages[key]
+	}
+	for i := range parsedSources.Structs {
+		st := parsedSources.Structs[i]
+		if !annotation.Has(st.DocLines, ut.annotation) {
+			continue
+		}
+		if ut.scope == ScopeStruct {
+			datas = append(datas, TemplateData{PackageName: st.PackageName, Annotation: ut.annotation, Struct: &st, Structs: []model.Struct{st}, Model: parsedSources})
+		} else {
+			d := pkgData(st.PackageName, st.Filename)
+			d.Structs = append(d.Structs, st)
+		}
+	}
+	if ut.scope == ScopePackage {
+		for _, op := range parsedSources.Operations {
+			if annotation.Has(op.DocLines, ut.annotation) {
+				d := pkgData(op.PackageName, op.Filename)
+				d.Operations = append(d.Operations, op)
+			}
+		}
+		for _, it := range parsedSources.Interfaces {
+			if annotation.Has(it.DocLines, ut.annotation) {
+				d := pkgData(it.PackageName, it.Filename)
+				d.Interfaces = append(d.Interfaces, it)
+			}
+		}
+		for _, key := range order {
+			datas = append(datas, *packages[key])
+		}
+	}
+	return datas
+}
+
+func (ut userTemplate) generate(data TemplateData) (generator.File, error) {
+	output, err := ut.outputFilename(data)
+	if err != nil {
+		return generator.File{}, err
+	}
+	return util.Generate(util.Info{
+		Data:           data,
+		Sources:        data.sources(),
+		TargetFilename: output,
+		TemplateName:   filepath.Base(ut.filename),
+		TemplateString: ut.body,
+		FuncMap:        customTemplateFuncs,
+	})
+}
+
+func (ut userTemplate) outputFilename(data TemplateData) (string, error) {
+	t, err := template.New("output").Funcs(customTemplateFuncs).Parse(ut.output)
+	if err != nil {
+		return "", fmt.Errorf("%s: output: %s", ut.filename, err)
+	}
+	var sb strings.Builder
+	if err = t.Execute(&sb, data); err != nil {
+		return "", fmt.Errorf("%s: output: %s", ut.filename, err)
+	}
+	name := strings.TrimSpace(sb.String())
+	// 只拒绝指向源文件目录之外的路径，gen_a..b.go 是合法的文件名
+	if p := filepath.Clean(name); name == "" || filepath.IsAbs(name) || p == ".." || strings.HasPrefix(p, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("%s: invalid output filename %q", ut.filename, name)
+	}
+	var src string
+	if data.Struct != nil {
+		src = data.Struct.Filename
+	} else if len(data.Structs) > 0 {
+		src = data.Structs[0].Filename
+	} else if len(data.Operations) > 0 {
+		src = data.Operations[0].Filename
+	} else if len(data.Interfaces) > 0 {
+		src = data.Interfaces[0].Filename
+	}
+	return filepath.Join(filepath.Dir(src), name), nil
+}
+
+func (data TemplateData) sources() []string {
+	var sources []string
+	add := func(filename string) {
+		if !slices.Contains(sources, filename) {
+			sources = append(sources, filename)
+		}
+	}
+	for _, st := range data.Structs {
+		add(st.Filename)
+	}
+	for _, op := range data.Operations {
+		add(op.Filename)
+	}
+	for _, it := range data.Interfaces {
+		add(it.Filename)
+	}
+	return sources
+}
+
+var customTemplateFuncs = template.FuncMap{
+	"lower":      strings.ToLower,
+	"upper":      strings.ToUpper,
+	"lowerFirst": lowerFirst,
+	"upperFirst": upperFirst,
+	"join":       strings.Join,
+	"hasPrefix":  strings.HasPrefix,
+	"trimPrefix": strings.TrimPrefix,
+	"hasAnnotation": func(docLines []string, name string) bool {
+		return annotation.Has(docLines, name)
+	},
+	"annotationArgs": func(docLines []string, name string) map[string]string {
+		a, _ := annotation.Find(docLines, name)
+		return a.Args
+	},
+}
+
+func lowerFirst(s string) string {
+	if s == "" {
+		return s
+	}
+	return strings.ToLower(s[:1]) + s[1:]
+}
+
+func upperFirst(s string) string {
+	if s == "" {
+		return s
+	}
+	return strings.ToUpper(s[:1]) + s[1:]
+}
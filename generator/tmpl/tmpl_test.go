@@ -0,0 +1,109 @@
+package tmpl
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/bwb0101/goAnnotations/model"
+)
+
+func writeTemplate(t *testing.T, content string) string {
+	t.Helper()
+	filename := filepath.Join(t.TempDir(), "repo.tmpl")
+	if err := os.WriteFile(filename, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return filename
+}
+
+func TestLoadTemplate(t *testing.T) {
+	filename := writeTemplate(t, "---\nannotation: @Repository\nscope: package\n\noutput: gen_{{.PackageName}}_repository.go\n---\npackage {{.PackageName}}\n")
+	ut, err := loadTemplate(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := userTemplate{
+		filename:   filename,
+		annotation: "@Repository",
+		scope:      ScopePackage,
+		output:     "gen_{{.PackageName}}_repository.go",
+		body:       "package {{.PackageName}}\n",
+	}
+	if ut != want {
+		t.Errorf("loadTemplate = %+v, want %+v", ut, want)
+	}
+}
+
+func TestLoadTemplateDefaultScope(t *testing.T) {
+	ut, err := loadTemplate(writeTemplate(t, "---\nannotation: @Repository\noutput: gen_a.go\n---\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ut.scope != ScopeStruct {
+		t.Errorf("scope = %q, want %q", ut.scope, ScopeStruct)
+	}
+}
+
+func TestLoadTemplateErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    string
+	}{
+		{name: "missing front-matter", content: "package p\n", want: "missing front-matter"},
+		{name: "not closed", content: "---\nannotation: @Repository\noutput: gen_a.go\n", want: "not closed"},
+		{name: "not key value", content: "---\nannotation\n---\n", want: ":2: expected key: value"},
+		{name: "unknown key", content: "---\nfoo: bar\n---\n", want: ":2: unknown front-matter key foo"},
+		{name: "annotation without @", content: "---\nannotation: Repository\noutput: gen_a.go\n---\n", want: "annotation must start with @"},
+		{name: "unknown scope", content: "---\nannotation: @Repository\nscope: file\noutput: gen_a.go\n---\n", want: "scope must be"},
+		{name: "missing output", content: "---\nannotation: @Repository\n---\n", want: "missing output"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := loadTemplate(writeTemplate(t, tt.content))
+			if err == nil {
+				t.Fatal("expected error")
+			}
+			if !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("error %q does not contain %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestOutputFilename(t *testing.T) {
+	data := TemplateData{PackageName: "user", Struct: &model.Struct{Name: "User", Filename: filepath.Join("src", "user", "user.go")}}
+	tests := []struct {
+		output string
+		want   string // 为空时应返回错误
+	}{
+		{output: "gen_{{lowerFirst .Struct.Name}}_repository.go", want: filepath.Join("src", "user", "gen_user_repository.go")},
+		{output: "gen_a..b.go", want: filepath.Join("src", "user", "gen_a..b.go")},
+		{output: "sub/../gen_a.go", want: filepath.Join("src", "user", "gen_a.go")},
+		{output: "../gen_a.go"},
+		{output: "sub/../../gen_a.go"},
+		{output: ".."},
+		{output: "/tmp/gen_a.go"},
+		{output: "{{if false}}x{{end}}"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.output, func(t *testing.T) {
+			ut := userTemplate{filename: "repo.tmpl", output: tt.output}
+			got, err := ut.outputFilename(data)
+			if tt.want == "" {
+				if err == nil {
+					t.Errorf("outputFilename = %q, want error", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("outputFilename = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,111 @@
+package generator
+
+import (
+	"fmt"
+	"strings"
+)
+
+const diffContext = 3
+
+// UnifiedDiff 按行比较 a 和 b，返回 unified diff 格式的文本，没有差异时返回空串
+func UnifiedDiff(aName, bName string, a, b []byte) string {
+	if string(a) == string(b) {
+		return ""
+	}
+	al, bl := splitLines(string(a)), splitLines(string(b))
+
+	// lcs[i][j] = al[i:] 与 bl[j:] 的最长公共子序列长度
+	lcs := make([][]int, len(al)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(bl)+1)
+	}
+	for i := len(al) - 1; i >= 0; i-- {
+		for j := len(bl) - 1; j >= 0; j-- {
+			if al[i] == bl[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	type edit struct {
+		op   byte // ' ', '-', '+'
+		line string
+		ai   int // 对应 a 中的行号(从 0 开始)
+		bi   int
+	}
+	var edits []edit
+	i, j := 0, 0
+	for i < len(al) || j < len(bl) {
+		switch {
+		case i < len(al) && j < len(bl) && al[i] == bl[j]:
+			edits = append(edits, edit{' ', al[i], i, j})
+			i++
+			j++
+		case i < len(al) && (j == len(bl) || lcs[i+1][j] >= lcs[i][j+1]):
+			edits = append(edits, edit{'-', al[i], i, j})
+			i++
+		default:
+			edits = append(edits, edit{'+', bl[j], i, j})
+			j++
+		}
+	}
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "--- %s\n+++ %s\n", aName, bName)
+	for start := 0; start < len(edits); {
+		// 找到下一处修改
+		for start < len(edits) && edits[start].op == ' ' {
+			start++
+		}
+		if start == len(edits) {
+			break
+		}
+		// 向后扩展，直到出现超过 2*diffContext 行的不变区域
+		end := start
+		for k := start; k < len(edits); k++ {
+			if edits[k].op != ' ' {
+				end = k + 1
+			} else if k-end >= 2*diffContext {
+				break
+			}
+		}
+		from := max(start-diffContext, 0)
+		to := min(end+diffContext, len(edits))
+		aStart, bStart, aCount, bCount := edits[from].ai, edits[from].bi, 0, 0
+		for _, e := range edits[from:to] {
+			if e.op != '+' {
+				aCount++
+			}
+			if e.op != '-' {
+				bCount++
+			}
+		}
+		fmt.Fprintf(&sb, "@@ -%s +%s @@\n", hunkRange(aStart, aCount), hunkRange(bStart, bCount))
+		for _, e := range edits[from:to] {
+			sb.WriteByte(e.op)
+			sb.WriteString(e.line)
+			sb.WriteByte('\n')
+		}
+		start = to
+	}
+	return sb.String()
+}
+
+func hunkRange(start, count int) string {
+	if count == 0 {
+		return fmt.Sprintf("%d,0", start)
+	}
+	if count == 1 {
+		return fmt.Sprintf("%d", start+1)
+	}
+	return fmt.Sprintf("%d,%d", start+1, count)
+}
+
+func splitLines(s string) []string {
+	if s == "" {
+		return nil
+	}
+	return strings.Split(strings.TrimSuffix(s, "\n"), "\n")
+}
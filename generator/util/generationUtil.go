@@ -3,13 +3,12 @@ package util
 import (
 	"bytes"
 	"fmt"
-	"go/format"
 	"os"
 	"path"
-	"path/filepath"
 	"strings"
 	"text/template"
 
+	"github.com/bwb0101/goAnnotations/generator"
 	"github.com/bwb0101/goAnnotations/model"
 )
 
@@ -92,7 +91,7 @@ func DetermineTargetPath(inputDir string, packageName string) (string, error) {
 }
 
 type Info struct {
-	Src            string
+	Sources        []string
 	TargetFilename string
 	TemplateName   string
 	TemplateString string
@@ -100,43 +99,21 @@ type Info struct {
 	Data           interface{}
 }
 
-func Generate(twd Info) error {
-	// _, _ = fmt.Fprintf(os.Stderr, "%s: Generated go file '%s' based on source '%s'\n", "Annotations", twd.TargetFilename, twd.Src)
-
-	w, err := createFile(twd.TargetFilename)
-	if err != nil {
-		return err
-	}
-	defer w.Close()
-
+// Generate 渲染模板，返回待写入的文件(格式化和写入由 generator.Writer 负责)
+func Generate(twd Info) (generator.File, error) {
 	t := template.New(twd.TemplateName).Funcs(twd.FuncMap)
-	t, err = t.Parse(twd.TemplateString)
+	t, err := t.Parse(twd.TemplateString)
 	if err != nil {
-		return err
+		return generator.File{}, err
 	}
-	t.DefinedTemplates()
 
 	var b bytes.Buffer
 	if err = t.Execute(&b, twd.Data); err != nil {
-		return err
-	}
-	if bs, err := format.Source(b.Bytes()); err != nil {
-		return err
-	} else if _, err = w.Write(bs); err != nil {
-		return err
-	}
-
-	return nil
-}
-
-func createFile(filename string) (*os.File, error) {
-	err := os.MkdirAll(filepath.Dir(filename), 0777)
-	if err != nil {
-		return nil, err
-	}
-	w, err := os.Create(filename)
-	if err != nil {
-		return nil, err
+		return generator.File{}, err
 	}
-	return w, nil
+	return generator.File{
+		Path:    twd.TargetFilename,
+		Content: b.Bytes(),
+		Sources: twd.Sources,
+	}, nil
 }
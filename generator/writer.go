@@ -0,0 +1,158 @@
+package generator
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"go/format"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+type ownedFile struct {
+	File
+	generator string
+}
+
+// Writer 收集所有生成器的输出，检查冲突后统一写入
+type Writer struct {
+	files      map[string]ownedFile
+	generators map[string]bool // 调用过 Add 的生成器，即这次运行了的
+}
+
+func NewWriter() *Writer {
+	return &Writer{files: map[string]ownedFile{}, generators: map[string]bool{}}
+}
+
+// Add 给 generatorName 产出的 .go 文件加上文件头并格式化；与已添加的文件路径相同时返回错误
+func (w *Writer) Add(generatorName string, files []File) error {
+	w.generators[generatorName] = true
+	for _, f := range files {
+		path := filepath.Clean(f.Path)
+		if prev, ok := w.files[path]; ok {
+			return fmt.Errorf("conflict: %s is generated by both %s and %s", path, prev.generator, generatorName)
+		}
+		if strings.HasSuffix(path, ".go") {
+			bs, err := format.Source(withHeader(generatorName, f))
+			if err != nil {
+				return fmt.Errorf("%s: error formatting %s: %s", generatorName, path, err)
+			}
+			f.Content = bs
+		}
+		f.Path = path
+		w.files[path] = ownedFile{File: f, generator: generatorName}
+	}
+	return nil
+}
+
+// Files 按路径排序返回已添加的文件
+func (w *Writer) Files() []File {
+	files := make([]File, 0, len(w.files))
+	for _, f := range w.files {
+		files = append(files, f.File)
+	}
+	sort.Slice(files, func(i, j int) bool { return files[i].Path < files[j].Path })
+	return files
+}
+
+// WriteStats Write 的结果统计
+type WriteStats struct {
+	Created   int
+	Updated   int
+	Unchanged int
+}
+
+func (s WriteStats) String() string {
+	return fmt.Sprintf("%d created, %d updated, %d unchanged", s.Created, s.Updated, s.Unchanged)
+}
+
+// Write 写入所有文件；内容与磁盘上相同(不计工具版本)的文件不会被重写，保持 mtime 不变
+func (w *Writer) Write() (WriteStats, error) {
+	stats := WriteStats{}
+	for _, f := range w.Files() {
+		old, err := os.ReadFile(f.Path)
+		switch {
+		case err == nil && bytes.Equal(WithoutVersion(old), WithoutVersion(f.Content)):
+			stats.Unchanged++
+			continue
+		case err == nil:
+			stats.Updated++
+		case errors.Is(err, fs.ErrNotExist):
+			stats.Created++
+		default:
+			return stats, err
+		}
+		if err = writeFileAtomic(f.Path, f.Content); err != nil {
+			return stats, err
+		}
+	}
+	return stats, nil
+}
+
+// Diff 与磁盘上的文件对比，返回每个有差异的文件的 unified diff，不写任何文件；只有工具版本不同时不算差异
+func (w *Writer) Diff() ([]string, error) {
+	var diffs []string
+	for _, f := range w.Files() {
+		old, err := os.ReadFile(f.Path)
+		if err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return nil, err
+		}
+		if err == nil && bytes.Equal(WithoutVersion(old), WithoutVersion(f.Content)) {
+			continue
+		}
+		if d := UnifiedDiff(f.Path+" (on disk)", f.Path+" (generated)", old, f.Content); d != "" {
+			diffs = append(diffs, d)
+		}
+	}
+	return diffs, nil
+}
+
+// Stale 返回 dirs 中由这次运行了的生成器生成、但这次没有产出的文件；
+// 文件头中没有记录生成器(旧版本生成的)或其他生成器的文件不算过时
+func (w *Writer) Stale(dirs []string) ([]string, error) {
+	owned, err := FindOwned(dirs)
+	if err != nil {
+		return nil, err
+	}
+	var stale []string
+	for _, filename := range owned {
+		if _, ok := w.files[filepath.Clean(filename)]; ok {
+			continue
+		}
+		name, err := OwnerGenerator(filename)
+		if err != nil {
+			return nil, err
+		}
+		if w.generators[name] {
+			stale = append(stale, filename)
+		}
+	}
+	return stale, nil
+}
+
+// writeFileAtomic 先写同目录下的临时文件再改名，中途失败不会留下写了一半的文件
+func writeFileAtomic(filename string, content []byte) error {
+	dir := filepath.Dir(filename)
+	if err := os.MkdirAll(dir, 0777); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(dir, "."+filepath.Base(filename)+".tmp*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err = tmp.Write(content); err != nil {
+		_ = tmp.Close()
+		return err
+	}
+	if err = tmp.Close(); err != nil {
+		return err
+	}
+	if err = os.Chmod(tmp.Name(), 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), filename)
+}
@@ -0,0 +1,122 @@
+package generator
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+)
+
+func goFile(dir, name, content string) File {
+	return File{Path: filepath.Join(dir, name), Content: []byte("package p\n\n" + content + "\n")}
+}
+
+func write(t *testing.T, generatorName string, files ...File) WriteStats {
+	t.Helper()
+	w := NewWriter()
+	if err := w.Add(generatorName, files); err != nil {
+		t.Fatal(err)
+	}
+	stats, err := w.Write()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return stats
+}
+
+func TestWriteSkipsUnchanged(t *testing.T) {
+	dir := t.TempDir()
+	f := goFile(dir, "gen_a.go", "var A = 1")
+	if stats := write(t, "api", f); stats.Created != 1 {
+		t.Fatalf("first write: %s", stats)
+	}
+	old := time.Now().Add(-time.Hour).Truncate(time.Second)
+	if err := os.Chtimes(f.Path, old, old); err != nil {
+		t.Fatal(err)
+	}
+
+	if stats := write(t, "api", f); stats.Unchanged != 1 {
+		t.Fatalf("same content: %s", stats)
+	}
+	if fi, err := os.Stat(f.Path); err != nil {
+		t.Fatal(err)
+	} else if !fi.ModTime().Equal(old) {
+		t.Fatalf("unchanged file rewritten: mtime %s, want %s", fi.ModTime(), old)
+	}
+
+	if stats := write(t, "api", goFile(dir, "gen_a.go", "var A = 2")); stats.Updated != 1 {
+		t.Fatalf("changed content: %s", stats)
+	}
+}
+
+func TestWriteIgnoresVersion(t *testing.T) {
+	dir := t.TempDir()
+	f := goFile(dir, "gen_a.go", "var A = 1")
+	defer func(v string) { Version = v }(Version)
+
+	Version = "v1.0.0"
+	write(t, "api", f)
+	Version = "v1.1.0"
+	if stats := write(t, "api", f); stats.Unchanged != 1 {
+		t.Fatalf("version only change: %s", stats)
+	}
+	w := NewWriter()
+	if err := w.Add("api", []File{f}); err != nil {
+		t.Fatal(err)
+	}
+	if diffs, err := w.Diff(); err != nil {
+		t.Fatal(err)
+	} else if len(diffs) != 0 {
+		t.Fatalf("version only change reported as diff:\n%s", diffs)
+	}
+}
+
+func TestStaleOnlyGeneratorsThatRan(t *testing.T) {
+	dir := t.TempDir()
+	write(t, "api", goFile(dir, "gen_a.go", "var A = 1"), goFile(dir, "gen_old.go", "var Old = 1"))
+	write(t, "echo", goFile(dir, "gen_echo.go", "var Echo = 1"))
+	legacy := filepath.Join(dir, "gen_legacy.go")
+	if err := os.WriteFile(legacy, []byte(legacyGenfileHeader+"\npackage p\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	handwritten := filepath.Join(dir, "gen_handwritten.go")
+	if err := os.WriteFile(handwritten, []byte("package p\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	w := NewWriter()
+	if err := w.Add("api", []File{goFile(dir, "gen_a.go", "var A = 1")}); err != nil {
+		t.Fatal(err)
+	}
+	stale, err := w.Stale([]string{dir})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{filepath.Join(dir, "gen_old.go")}; !slices.Equal(stale, want) {
+		t.Fatalf("Stale = %v, want %v", stale, want)
+	}
+
+	if stale, err = NewWriter().Stale([]string{dir}); err != nil {
+		t.Fatal(err)
+	} else if len(stale) != 0 {
+		t.Fatalf("no generator ran, Stale = %v", stale)
+	}
+}
+
+func TestOwnedDirs(t *testing.T) {
+	root := t.TempDir()
+	for _, dir := range []string{"a/b", "vendor/x", "testdata/x", ".git"} {
+		if err := os.MkdirAll(filepath.Join(root, dir), 0777); err != nil {
+			t.Fatal(err)
+		}
+	}
+	dirs, err := OwnedDirs(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{root, filepath.Join(root, "a"), filepath.Join(root, "a/b")}
+	if !slices.Equal(dirs, want) {
+		t.Fatalf("OwnedDirs = %v, want %v", dirs, want)
+	}
+}
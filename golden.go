@@ -0,0 +1,78 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+
+	"github.com/bwb0101/goAnnotations/generator"
+	"github.com/bwb0101/goAnnotations/generator/golden"
+	"github.com/bwb0101/goAnnotations/logger"
+)
+
+// runGolden 运行 testdata 中的 golden 用例，有差异时返回 1，出错返回 2
+func runGolden(args []string) int {
+	fs := newFlagSet("golden", "[-update] [-run <正则>] [testdata目录]\n 用例目录: <testdata>/<生成器>/<用例>/input 与 <testdata>/<生成器>/<用例>/expected")
+	update := fs.Bool("update", false, "用生成结果覆盖 expected 目录")
+	run := fs.String("run", "", "只运行名字(<生成器>/<用例>)匹配的用例")
+	_ = fs.Parse(args)
+	root := "testdata"
+	if fs.NArg() > 0 {
+		root = fs.Arg(0)
+	}
+	runRegex, err := regexp.Compile(*run)
+	if err != nil {
+		logger.Errorf("Error compiling -run: %s", err)
+		return exitError
+	}
+
+	cases, err := golden.Cases(root)
+	if err != nil {
+		logger.Errorf("Error reading %s: %s", root, err)
+		return exitError
+	}
+	code := exitOK
+	for _, c := range cases {
+		if !runRegex.MatchString(c.String()) {
+			continue
+		}
+		g, err := goldenGenerator(c)
+		if err != nil {
+			logger.Errorf("%s: %s", c, err)
+			return exitError
+		}
+		r, err := golden.Check(c, g, *update)
+		if err != nil {
+			logger.Errorf("%s: %s", c, err)
+			return exitError
+		}
+		switch {
+		case *update:
+			logger.Infof("updated %s (%d files)", c, len(r.Updated))
+		case r.OK():
+			logger.Infof("ok      %s", c)
+		default:
+			fmt.Printf("FAIL    %s\n", c)
+			for _, d := range r.Diffs {
+				fmt.Print(d)
+			}
+			code = exitFailure
+		}
+	}
+	return code
+}
+
+// goldenGenerator 按用例的配置创建生成器：内置生成器、配置中的插件或 PATH 中的插件
+func goldenGenerator(c golden.Case) (generator.Generator, error) {
+	cfg, err := c.Config()
+	if err != nil {
+		return nil, err
+	}
+	if e, ok := registry.Lookup(c.Generator); ok {
+		return e.New(cfg), nil
+	}
+	e, err := pluginEntry(cfg, c.Generator)
+	if err != nil {
+		return nil, err
+	}
+	return e.New(cfg), nil
+}
@@ -0,0 +1,22 @@
+package main
+
+import (
+	"flag"
+	"testing"
+
+	"github.com/bwb0101/goAnnotations/generator/golden"
+)
+
+var update = flag.Bool("update", false, "用生成结果覆盖 testdata 中的 expected 目录")
+
+func TestGoldenApi(t *testing.T) {
+	golden.Run(t, "testdata", "api", goldenGenerator, *update)
+}
+
+func TestGoldenModel(t *testing.T) {
+	golden.Run(t, "testdata", "model", goldenGenerator, *update)
+}
+
+func TestGoldenOpenApi(t *testing.T) {
+	golden.Run(t, "testdata", "openapi", goldenGenerator, *update)
+}
@@ -0,0 +1,61 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/bwb0101/goAnnotations/config"
+	"github.com/bwb0101/goAnnotations/logger"
+)
+
+const initHeader = `# goAnnotations 项目配置，作用于本目录及子目录。相对路径相对于本文件所在的目录
+#
+# generators:          # 按名字开关生成器、传递选项
+#   model:
+#     enabled: false
+#   openapi:           # 默认不运行
+#     enabled: true
+# openapi:
+#   output: openapi.yaml
+# plugins:             # 进程外生成器，path 为空时在 PATH 中查找 goannotations-gen-<name>
+#   echo:
+#     path: ./bin/goannotations-gen-echo
+# templatesDir: .goannotations/templates
+#
+`
+
+// runInit 在目录中创建带默认值的 goannotations.yaml
+func runInit(args []string) int {
+	flags := newFlagSet("init", "[-dir <目录>] [-force]")
+	initDir := flags.String("dir", ".", "配置文件所在的目录")
+	force := flags.Bool("force", false, "覆盖已有的配置文件")
+	_ = flags.Parse(args)
+
+	filename := filepath.Join(*initDir, config.Filenames[0])
+	if _, err := os.Stat(filename); err == nil && !*force {
+		logger.Errorf("%s already exists, use -force to overwrite", filename)
+		return exitError
+	} else if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		logger.Errorf("Error checking %s: %s", filename, err)
+		return exitError
+	}
+	var buf bytes.Buffer
+	buf.WriteString(initHeader)
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	err := enc.Encode(config.Default())
+	if err == nil {
+		err = os.WriteFile(filename, buf.Bytes(), 0644)
+	}
+	if err != nil {
+		logger.Errorf("Error writing %s: %s", filename, err)
+		return exitError
+	}
+	logger.Infof("Created %s", filename)
+	return exitOK
+}
@@ -0,0 +1,262 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/bwb0101/goAnnotations/annotation"
+	"github.com/bwb0101/goAnnotations/generator"
+	"github.com/bwb0101/goAnnotations/logger"
+	"github.com/bwb0101/goAnnotations/model"
+	"github.com/bwb0101/goAnnotations/parser"
+)
+
+// runInspect 输出解析得到的 ParsedSources，json 格式可以被 model.Parse 读回
+func runInspect(args []string) int {
+	fs := newFlagSet("inspect", "[-dir <目录>] [-r] [-format json|tree|table] [-package p1,p2] [-kind struct,operation] [-annotation @Handler]")
+	inspectDir := fs.String("dir", ".", "要解析的目录")
+	recursive := fs.Bool("r", false, "包括子目录")
+	format := fs.String("format", "tree", "输出格式: json/tree/table")
+	packages := fs.String("package", "", "只输出这些包，逗号分隔")
+	kinds := fs.String("kind", "", "只输出这些种类，逗号分隔: "+strings.Join(model.Kinds, ","))
+	annotationName := fs.String("annotation", "", "只输出带该注解的元素，例如 @Handler")
+	_ = fs.Parse(args)
+
+	filter := model.Filter{Packages: splitList(*packages), Kinds: splitList(*kinds), Annotation: *annotationName}
+	for _, k := range filter.Kinds {
+		if !slices.Contains(model.Kinds, k) {
+			logger.Errorf("unknown kind %s (available: %s)", k, strings.Join(model.Kinds, ","))
+			return exitError
+		}
+	}
+	if filter.Annotation != "" && !strings.HasPrefix(filter.Annotation, "@") {
+		filter.Annotation = "@" + filter.Annotation
+	}
+
+	parse := parser.ParseSourceDir
+	if *recursive {
+		parse = parser.ParseSourceTree
+	}
+	parsedSources, err := parse(*inspectDir, "^.*.go$", generator.GenfileExcludePattern)
+	if err != nil {
+		logger.Errorf("Error parsing %s: %s", *inspectDir, err)
+		return exitError
+	}
+	parsedSources = parsedSources.Filter(filter)
+
+	switch *format {
+	case "json":
+		err = inspectJson(os.Stdout, parsedSources)
+	case "tree":
+		err = inspectTree(os.Stdout, parsedSources)
+	case "table":
+		err = inspectTable(os.Stdout, parsedSources)
+	default:
+		logger.Errorf("unknown format %s", *format)
+		return exitError
+	}
+	if err != nil {
+		logger.Errorf("Error writing output: %s", err)
+		return exitError
+	}
+	return exitOK
+}
+
+func splitList(s string) []string {
+	var list []string
+	for _, v := range strings.Split(s, ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			list = append(list, v)
+		}
+	}
+	return list
+}
+
+func inspectJson(w io.Writer, parsedSources model.ParsedSources) error {
+	b, err := json.MarshalIndent(parsedSources, "", "\t")
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(w, "%s\n", b)
+	return err
+}
+
+// inspectTree 按包输出元素；struct 的方法列在 struct 下，struct 被过滤掉时列在包下
+func inspectTree(w io.Writer, ps model.ParsedSources) error {
+	bw := &errWriter{w: w}
+	listed := map[string]bool{} // 已经输出的 struct，包名.名字
+	for _, pkg := range packageNames(ps) {
+		bw.printf("package %s\n", pkg)
+		for _, s := range ps.Structs {
+			if s.PackageName != pkg {
+				continue
+			}
+			listed[pkg+"."+s.Name] = true
+			bw.printf("  struct %s (%s)\n", s.Name, filepath.Base(s.Filename))
+			printAnnotations(bw, "    ", s.DocLines)
+			for _, f := range s.Fields {
+				bw.printf("    %s\n", strings.TrimSpace(strings.Join([]string{f.Name, f.TypeName, f.Tag}, " ")))
+			}
+		}
+		for _, o := range ps.Operations {
+			if o.PackageName != pkg {
+				continue
+			}
+			indent := "  "
+			if o.RelatedStruct != nil && listed[pkg+"."+o.RelatedStruct.DereferencedTypeName()] {
+				indent = "    "
+			}
+			bw.printf("%s%s (%s)\n", indent, operationSignature(o), filepath.Base(o.Filename))
+			printAnnotations(bw, indent+"  ", o.DocLines)
+		}
+		for _, i := range ps.Interfaces {
+			if i.PackageName != pkg {
+				continue
+			}
+			bw.printf("  interface %s (%s)\n", i.Name, filepath.Base(i.Filename))
+			printAnnotations(bw, "    ", i.DocLines)
+			for _, m := range i.Methods {
+				bw.printf("    %s\n", strings.TrimPrefix(operationSignature(m), "func "))
+			}
+		}
+		for _, t := range ps.Typedefs {
+			if t.PackageName == pkg {
+				bw.printf("  type %s (%s)\n", strings.TrimSpace(t.Name+" "+t.Type), filepath.Base(t.Filename))
+				printAnnotations(bw, "    ", t.DocLines)
+			}
+		}
+		for _, e := range ps.Enums {
+			if e.PackageName != pkg {
+				continue
+			}
+			bw.printf("  enum %s (%s)\n", e.Name, filepath.Base(e.Filename))
+			printAnnotations(bw, "    ", e.DocLines)
+			for _, l := range e.EnumLiterals {
+				if l.Value != "" {
+					bw.printf("    %s = %s\n", l.Name, l.Value)
+				} else {
+					bw.printf("    %s\n", l.Name)
+				}
+			}
+		}
+	}
+	return bw.err
+}
+
+func inspectTable(w io.Writer, ps model.ParsedSources) error {
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	row := func(kind, pkg, name, filename string, docLines []string) {
+		_, _ = fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\n", kind, pkg, name, filename, strings.Join(annotationNames(docLines), ","))
+	}
+	row("KIND", "PACKAGE", "NAME", "FILE", nil)
+	for _, s := range ps.Structs {
+		row(model.KindStruct, s.PackageName, s.Name, s.Filename, s.DocLines)
+	}
+	for _, o := range ps.Operations {
+		name := o.Name
+		if o.RelatedStruct != nil {
+			name = o.RelatedStruct.DereferencedTypeName() + "." + o.Name
+		}
+		row(model.KindOperation, o.PackageName, name, o.Filename, o.DocLines)
+	}
+	for _, i := range ps.Interfaces {
+		row(model.KindInterface, i.PackageName, i.Name, i.Filename, i.DocLines)
+	}
+	for _, t := range ps.Typedefs {
+		row(model.KindTypedef, t.PackageName, t.Name, t.Filename, t.DocLines)
+	}
+	for _, e := range ps.Enums {
+		row(model.KindEnum, e.PackageName, e.Name, e.Filename, e.DocLines)
+	}
+	return tw.Flush()
+}
+
+func packageNames(ps model.ParsedSources) []string {
+	var names []string
+	add := func(pkg string) {
+		if !slices.Contains(names, pkg) {
+			names = append(names, pkg)
+		}
+	}
+	for _, s := range ps.Structs {
+		add(s.PackageName)
+	}
+	for _, o := range ps.Operations {
+		add(o.PackageName)
+	}
+	for _, i := range ps.Interfaces {
+		add(i.PackageName)
+	}
+	for _, t := range ps.Typedefs {
+		add(t.PackageName)
+	}
+	for _, e := range ps.Enums {
+		add(e.PackageName)
+	}
+	slices.Sort(names)
+	return names
+}
+
+func operationSignature(o model.Operation) string {
+	args := func(fields []model.Field) string {
+		list := make([]string, 0, len(fields))
+		for _, f := range fields {
+			list = append(list, strings.TrimSpace(f.Name+" "+f.TypeName))
+		}
+		return strings.Join(list, ", ")
+	}
+	sig := "func "
+	if o.RelatedStruct != nil {
+		sig += "(" + args([]model.Field{*o.RelatedStruct}) + ") "
+	}
+	sig += o.Name + "(" + args(o.InputArgs) + ")"
+	switch outs := args(o.OutputArgs); {
+	case len(o.OutputArgs) == 1 && o.OutputArgs[0].Name == "":
+		sig += " " + outs
+	case outs != "":
+		sig += " (" + outs + ")"
+	}
+	return sig
+}
+
+// annotationNames 文档注释中的注解名
+func annotationNames(docLines []string) []string {
+	var names []string
+	for _, line := range docLines {
+		if a, ok := parseAnnotation(line); ok {
+			names = append(names, a)
+		}
+	}
+	return names
+}
+
+func printAnnotations(bw *errWriter, indent string, docLines []string) {
+	for _, line := range docLines {
+		if _, ok := parseAnnotation(line); ok {
+			bw.printf("%s%s\n", indent, strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(line), "//")))
+		}
+	}
+}
+
+func parseAnnotation(line string) (string, bool) {
+	a, ok := annotation.ParseLine(line)
+	return a.Name, ok
+}
+
+// errWriter 记住第一个写入错误
+type errWriter struct {
+	w   io.Writer
+	err error
+}
+
+func (ew *errWriter) printf(format string, args ...any) {
+	if ew.err == nil {
+		_, ew.err = fmt.Fprintf(ew.w, format, args...)
+	}
+}
@@ -0,0 +1,50 @@
+// Package logger 命令行的日志输出，按 -q/-v 控制输出多少；底层使用标准库 log，前缀等设置仍然有效
+package logger
+
+import (
+	"log"
+)
+
+type Level int
+
+const (
+	Quiet   Level = iota // -q: 只输出错误
+	Normal               // 默认: 错误、警告和结果
+	Verbose              // -v: 额外输出过程信息
+)
+
+var level = Normal
+
+func SetLevel(l Level) {
+	level = l
+}
+
+func GetLevel() Level {
+	return level
+}
+
+// Errorf 错误，总是输出
+func Errorf(format string, args ...any) {
+	log.Printf(format, args...)
+}
+
+// Warnf 警告，-q 时不输出
+func Warnf(format string, args ...any) {
+	if level >= Normal {
+		log.Printf("warning: "+format, args...)
+	}
+}
+
+// Infof 结果信息，-q 时不输出
+func Infof(format string, args ...any) {
+	if level >= Normal {
+		log.Printf(format, args...)
+	}
+}
+
+// Debugf 过程信息，只在 -v 时输出
+func Debugf(format string, args ...any) {
+	if level >= Verbose {
+		log.Printf(format, args...)
+	}
+}
@@ -0,0 +1,32 @@
+package main
+
+import (
+	"log"
+	"os"
+
+	"github.com/bwb0101/goAnnotations/logger"
+	"github.com/bwb0101/goAnnotations/lsp"
+)
+
+// runLsp 通过 stdio 运行注解的语言服务器，退出码按 LSP 规范
+func runLsp(args []string) int {
+	fs := newFlagSet("lsp", "[-logfile <文件>]")
+	logFile := fs.String("logfile", "", "日志文件，默认不输出日志(stdout 用于通讯)")
+	_ = fs.Parse(args)
+
+	var lspLogger *log.Logger
+	if *logFile != "" {
+		f, err := os.OpenFile(*logFile, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+		if err != nil {
+			logger.Errorf("Error opening log file: %s", err)
+			return exitError
+		}
+		defer f.Close()
+		lspLogger = log.New(f, "lsp: ", log.LstdFlags)
+	}
+	code, err := lsp.NewServer(os.Stdin, os.Stdout, lspLogger).Run()
+	if err != nil {
+		logger.Errorf("Error running language server: %s", err)
+	}
+	return code
+}
@@ -0,0 +1,363 @@
+package lsp
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"go/ast"
+	goParser "go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"strings"
+	"unicode"
+
+	"github.com/bwb0101/goAnnotations/annotation"
+	"github.com/bwb0101/goAnnotations/generator/api"
+)
+
+const source = "goAnnotations"
+
+// --------------------------------------------------- diagnostics ---------------------------------------------------
+
+// diagnostics 只检查已注册 Schema 的注解，其他 @xxx 可能是别的工具使用的
+func diagnostics(lines []string) []Diagnostic {
+	list := []Diagnostic{}
+	for i, line := range lines {
+		a, ok, err := annotation.Parse(line)
+		if !ok {
+			continue
+		}
+		schema, ok := annotation.Lookup(a.Name)
+		if !ok {
+			continue
+		}
+		errs := schema.Validate(a)
+		if err != nil { // 语法错误之后的参数没有解析，不报缺少必填参数
+			errs = []error{err}
+		}
+		for _, err := range errs {
+			var se *annotation.SyntaxError
+			if !errors.As(err, &se) {
+				continue
+			}
+			start, end := errorSpan(line, a, se.Offset)
+			list = append(list, Diagnostic{
+				Range:    lineRange(lines, i, start, end),
+				Severity: SeverityError,
+				Source:   source,
+				Message:  se.Msg,
+			})
+		}
+	}
+	return list
+}
+
+// errorSpan 出错位置对应的 key、值或注解名，都不是时到行尾
+func errorSpan(line string, a annotation.Annotation, offset int) (int, int) {
+	if offset == a.Pos {
+		return a.Pos, a.NameEnd
+	}
+	for _, arg := range a.List {
+		switch offset {
+		case arg.KeyPos:
+			return arg.KeyPos, arg.KeyPos + len(arg.Key)
+		case arg.ValuePos:
+			return arg.ValuePos, arg.ValueEnd
+		}
+	}
+	if offset >= len(line) {
+		return len(line), len(line)
+	}
+	return offset, len(line)
+}
+
+// --------------------------------------------------- completion ---------------------------------------------------
+
+const (
+	contextName = iota
+	contextKey
+	contextValue
+)
+
+type completionContext struct {
+	kind   int
+	start  int // 要替换的文字的起始字节
+	schema annotation.Schema
+	key    string
+	used   map[string]bool
+	quoted bool // 值已经有左引号
+}
+
+// findContext 分析光标前的文字，判断是在补全注解名、key 还是值
+func findContext(line string, cursor int) (completionContext, bool) {
+	var c completionContext
+	comment := strings.Index(line, "//")
+	if comment < 0 || cursor < comment+2 {
+		return c, false
+	}
+	prefix := line[:cursor]
+	at := strings.LastIndex(prefix, "@")
+	if at < comment || strings.TrimSpace(prefix[comment+2:at]) != "" {
+		return c, false
+	}
+	name := at + 1
+	for name < len(prefix) && isIdent(rune(prefix[name])) {
+		name++
+	}
+	if name == len(prefix) {
+		return completionContext{kind: contextName, start: at}, true
+	}
+	if prefix[name] != '(' {
+		return c, false
+	}
+	schema, ok := annotation.Lookup(prefix[at:name])
+	if !ok {
+		return c, false
+	}
+	c = completionContext{schema: schema, used: map[string]bool{}}
+
+	// 按不在引号内的逗号拆分参数，最后一段是光标所在的参数
+	segStart, inQuote := name+1, false
+	for i := name + 1; i < len(prefix); i++ {
+		switch ch := prefix[i]; {
+		case ch == '\\' && inQuote:
+			i++
+		case ch == '"':
+			inQuote = !inQuote
+		case ch == ')' && !inQuote:
+			return c, false
+		case ch == ',' && !inQuote:
+			if k, _, ok := strings.Cut(prefix[segStart:i], "="); ok {
+				c.used[strings.TrimSpace(k)] = true
+			}
+			segStart = i + 1
+		}
+	}
+	seg := prefix[segStart:]
+	offset := segStart + len(seg) - len(strings.TrimLeft(seg, " \t"))
+	if k, v, ok := strings.Cut(seg, "="); ok {
+		c.kind, c.key = contextValue, strings.TrimSpace(k)
+		c.start = cursor - len(strings.TrimLeft(v, " \t"))
+		if strings.HasPrefix(line[c.start:cursor], "\"") {
+			c.start++
+			c.quoted = true
+		}
+		return c, true
+	}
+	c.kind, c.start = contextKey, offset
+	return c, true
+}
+
+func isIdent(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_' || r == '.'
+}
+
+func (s *Server) completion(p TextDocumentPositionParams) []CompletionItem {
+	items := []CompletionItem{}
+	lines, line, ok := s.line(p)
+	if !ok {
+		return items
+	}
+	cursor := byteOffset(line, p.Position.Character)
+	c, ok := findContext(line, cursor)
+	if !ok {
+		return items
+	}
+	replace := lineRange(lines, p.Position.Line, c.start, cursor)
+	switch c.kind {
+	case contextName:
+		for _, schema := range annotation.Schemas() {
+			items = append(items, CompletionItem{
+				Label:         schema.Name,
+				Kind:          CompletionKindKeyword,
+				Documentation: &MarkupContent{Kind: "plaintext", Value: schema.Doc},
+				TextEdit:      &TextEdit{Range: replace, NewText: schema.Name + "("},
+			})
+		}
+	case contextKey:
+		for _, k := range c.schema.Keys {
+			if c.used[k.Name] {
+				continue
+			}
+			detail := ""
+			if k.Required {
+				detail = "required"
+			}
+			items = append(items, CompletionItem{
+				Label:         k.Name,
+				Kind:          CompletionKindProperty,
+				Detail:        detail,
+				Documentation: &MarkupContent{Kind: "plaintext", Value: k.Doc},
+				TextEdit:      &TextEdit{Range: replace, NewText: k.Name + "=\""},
+			})
+		}
+	case contextValue:
+		k, ok := c.schema.Key(c.key)
+		if !ok {
+			return items
+		}
+		for _, v := range k.Values {
+			text := v + "\""
+			if !c.quoted {
+				text = "\"" + text
+			}
+			items = append(items, CompletionItem{
+				Label:    fmt.Sprintf("%q", v),
+				Kind:     CompletionKindValue,
+				Detail:   k.Name,
+				TextEdit: &TextEdit{Range: replace, NewText: text},
+			})
+		}
+	}
+	return items
+}
+
+// --------------------------------------------------- hover ---------------------------------------------------
+
+func (s *Server) hover(p TextDocumentPositionParams) *Hover {
+	lines, line, ok := s.line(p)
+	if !ok {
+		return nil
+	}
+	a, ok, _ := annotation.Parse(line)
+	if !ok {
+		return nil
+	}
+	schema, ok := annotation.Lookup(a.Name)
+	if !ok {
+		return nil
+	}
+	cursor := byteOffset(line, p.Position.Character)
+	if cursor >= a.Pos && cursor < a.NameEnd {
+		r := lineRange(lines, p.Position.Line, a.Pos, a.NameEnd)
+		return &Hover{Contents: MarkupContent{Kind: "plaintext", Value: schema.Doc}, Range: &r}
+	}
+	for _, arg := range a.List {
+		if cursor < arg.KeyPos || cursor >= arg.ValueEnd {
+			continue
+		}
+		k, ok := schema.Key(arg.Key)
+		if !ok {
+			return nil
+		}
+		doc := k.Name + ": " + k.Doc
+		if len(k.Values) > 0 {
+			doc += "\n可选值: " + strings.Join(k.Values, ", ")
+		}
+		r := lineRange(lines, p.Position.Line, arg.KeyPos, arg.ValueEnd)
+		return &Hover{Contents: MarkupContent{Kind: "plaintext", Value: doc}, Range: &r}
+	}
+	return nil
+}
+
+// --------------------------------------------------- definition ---------------------------------------------------
+
+// definition dataPtrStruct="path|pkg.struct" 跳转到结构体定义
+func (s *Server) definition(p TextDocumentPositionParams) []Location {
+	locations := []Location{}
+	_, line, ok := s.line(p)
+	if !ok {
+		return locations
+	}
+	a, ok, _ := annotation.Parse(line)
+	if !ok || a.Name != api.HandlerSchema.Name {
+		return locations
+	}
+	cursor := byteOffset(line, p.Position.Character)
+	for _, arg := range a.List {
+		if arg.Key != "dataPtrStruct" || cursor < arg.ValuePos || cursor >= arg.ValueEnd {
+			continue
+		}
+		importPath, typeName, err := api.SplitDataPtrStruct(arg.Value)
+		if err != nil {
+			return locations
+		}
+		filename, ok := uriToPath(p.TextDocument.URI)
+		if !ok {
+			return locations
+		}
+		dir, ok := resolveImport(filepath.Dir(filename), importPath)
+		if !ok {
+			s.logger.Printf("Cannot resolve import %s", importPath)
+			return locations
+		}
+		_, name, _ := strings.Cut(typeName, ".")
+		if loc, ok := findType(dir, name); ok {
+			locations = append(locations, loc)
+		}
+	}
+	return locations
+}
+
+// resolveImport 查找 import 路径对应的目录：先按所在模块的 go.mod，再逐级向上按 GOPATH 的方式查找
+func resolveImport(dir, importPath string) (string, bool) {
+	if root, module, ok := findModule(dir); ok {
+		if importPath == module {
+			return root, true
+		}
+		if rest, ok := strings.CutPrefix(importPath, module+"/"); ok {
+			return filepath.Join(root, filepath.FromSlash(rest)), true
+		}
+	}
+	for d := dir; ; {
+		candidate := filepath.Join(d, filepath.FromSlash(importPath))
+		if fi, err := os.Stat(candidate); err == nil && fi.IsDir() {
+			return candidate, true
+		}
+		parent := filepath.Dir(d)
+		if parent == d {
+			return "", false
+		}
+		d = parent
+	}
+}
+
+func findModule(dir string) (root, module string, ok bool) {
+	for d := dir; ; {
+		if f, err := os.Open(filepath.Join(d, "go.mod")); err == nil {
+			defer f.Close()
+			sc := bufio.NewScanner(f)
+			for sc.Scan() {
+				if m, ok := strings.CutPrefix(strings.TrimSpace(sc.Text()), "module "); ok {
+					return d, strings.Trim(strings.TrimSpace(m), `"`), true
+				}
+			}
+			return "", "", false
+		}
+		parent := filepath.Dir(d)
+		if parent == d {
+			return "", "", false
+		}
+		d = parent
+	}
+}
+
+func findType(dir, name string) (Location, bool) {
+	fset := token.NewFileSet()
+	pkgs, err := goParser.ParseDir(fset, dir, func(fi os.FileInfo) bool {
+		return !strings.HasSuffix(fi.Name(), "_test.go")
+	}, goParser.SkipObjectResolution)
+	if err != nil {
+		return Location{}, false
+	}
+	for _, pkg := range pkgs {
+		for _, f := range pkg.Files {
+			for _, decl := range f.Decls {
+				gd, ok := decl.(*ast.GenDecl)
+				if !ok || gd.Tok != token.TYPE {
+					continue
+				}
+				for _, spec := range gd.Specs {
+					if ts := spec.(*ast.TypeSpec); ts.Name.Name == name {
+						pos := fset.Position(ts.Name.Pos())
+						start := Position{Line: pos.Line - 1, Character: pos.Column - 1}
+						end := Position{Line: start.Line, Character: start.Character + len(name)}
+						return Location{URI: pathToURI(pos.Filename), Range: Range{Start: start, End: end}}, true
+					}
+				}
+			}
+		}
+	}
+	return Location{}, false
+}
@@ -0,0 +1,193 @@
+package lsp
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/textproto"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Server 注解的语言服务器，通过 stdio 使用 JSON-RPC 通讯。
+// 文档只支持全量同步，补全、悬停、诊断均基于 annotation 中注册的 Schema
+type Server struct {
+	in     *bufio.Reader
+	out    io.Writer
+	outMu  sync.Mutex
+	logger *log.Logger
+
+	docs     map[string][]string // uri -> 按行拆分的内容
+	shutdown bool
+}
+
+func NewServer(in io.Reader, out io.Writer, logger *log.Logger) *Server {
+	if logger == nil {
+		logger = log.New(io.Discard, "", 0)
+	}
+	return &Server{in: bufio.NewReader(in), out: out, logger: logger, docs: map[string][]string{}}
+}
+
+// Run 处理请求直到收到 exit 或输入结束，返回进程退出码：先 shutdown 再 exit 时为 0
+func (s *Server) Run() (int, error) {
+	for {
+		msg, err := s.read()
+		if err == io.EOF {
+			return 1, nil
+		}
+		if err != nil {
+			return 1, err
+		}
+		if msg.Method == "exit" {
+			if s.shutdown {
+				return 0, nil
+			}
+			return 1, nil
+		}
+		s.handle(msg)
+	}
+}
+
+func (s *Server) read() (message, error) {
+	var msg message
+	header, err := textproto.NewReader(s.in).ReadMIMEHeader()
+	if err != nil {
+		return msg, err
+	}
+	length, err := strconv.Atoi(header.Get("Content-Length"))
+	if err != nil {
+		return msg, fmt.Errorf("Error reading Content-Length: %s", err)
+	}
+	body := make([]byte, length)
+	if _, err = io.ReadFull(s.in, body); err != nil {
+		return msg, err
+	}
+	if err = json.Unmarshal(body, &msg); err != nil {
+		s.replyError(nil, codeParseError, err.Error())
+		return msg, nil
+	}
+	return msg, nil
+}
+
+func (s *Server) write(v any) {
+	b, err := json.Marshal(v)
+	if err != nil {
+		s.logger.Printf("Error encoding message: %s", err)
+		return
+	}
+	s.outMu.Lock()
+	defer s.outMu.Unlock()
+	if _, err = fmt.Fprintf(s.out, "Content-Length: %d\r\n\r\n%s", len(b), b); err != nil {
+		s.logger.Printf("Error writing message: %s", err)
+	}
+}
+
+func (s *Server) reply(id json.RawMessage, result any) {
+	s.write(response{JSONRPC: "2.0", ID: id, Result: result})
+}
+
+func (s *Server) replyError(id json.RawMessage, code int, msg string) {
+	if id == nil {
+		id = json.RawMessage("null")
+	}
+	s.write(errorResponse{JSONRPC: "2.0", ID: id, Error: responseError{Code: code, Message: msg}})
+}
+
+func (s *Server) notify(method string, params any) {
+	s.write(struct {
+		JSONRPC string `json:"jsonrpc"`
+		Method  string `json:"method"`
+		Params  any    `json:"params"`
+	}{"2.0", method, params})
+}
+
+func (s *Server) handle(msg message) {
+	if msg.Method == "" { // 客户端对我们请求的响应，不会出现
+		return
+	}
+	isRequest := msg.ID != nil
+	var err error
+	var result any
+	switch msg.Method {
+	case "initialize":
+		result = map[string]any{
+			"capabilities": map[string]any{
+				"textDocumentSync": 1, // Full
+				"completionProvider": map[string]any{
+					"triggerCharacters": []string{"@", "(", ",", " ", "\""},
+				},
+				"hoverProvider":      true,
+				"definitionProvider": true,
+			},
+			"serverInfo": map[string]any{"name": "goAnnotations"},
+		}
+	case "initialized", "$/cancelRequest", "$/setTrace", "workspace/didChangeConfiguration":
+	case "shutdown":
+		s.shutdown = true
+	case "textDocument/didOpen":
+		var p DidOpenTextDocumentParams
+		if err = json.Unmarshal(msg.Params, &p); err == nil {
+			s.update(p.TextDocument.URI, p.TextDocument.Text)
+		}
+	case "textDocument/didChange":
+		var p DidChangeTextDocumentParams
+		if err = json.Unmarshal(msg.Params, &p); err == nil && len(p.ContentChanges) > 0 {
+			s.update(p.TextDocument.URI, p.ContentChanges[len(p.ContentChanges)-1].Text)
+		}
+	case "textDocument/didClose":
+		var p DidCloseTextDocumentParams
+		if err = json.Unmarshal(msg.Params, &p); err == nil {
+			delete(s.docs, p.TextDocument.URI)
+			s.notify("textDocument/publishDiagnostics", PublishDiagnosticsParams{URI: p.TextDocument.URI, Diagnostics: []Diagnostic{}})
+		}
+	case "textDocument/completion":
+		var p TextDocumentPositionParams
+		if err = json.Unmarshal(msg.Params, &p); err == nil {
+			result = s.completion(p)
+		}
+	case "textDocument/hover":
+		var p TextDocumentPositionParams
+		if err = json.Unmarshal(msg.Params, &p); err == nil {
+			result = s.hover(p)
+		}
+	case "textDocument/definition":
+		var p TextDocumentPositionParams
+		if err = json.Unmarshal(msg.Params, &p); err == nil {
+			result = s.definition(p)
+		}
+	default:
+		if isRequest {
+			s.replyError(msg.ID, codeMethodNotFound, "method not found: "+msg.Method)
+		}
+		return
+	}
+	if !isRequest {
+		if err != nil {
+			s.logger.Printf("Error handling %s: %s", msg.Method, err)
+		}
+		return
+	}
+	if err != nil {
+		s.replyError(msg.ID, codeInvalidParams, err.Error())
+		return
+	}
+	s.reply(msg.ID, result)
+}
+
+func (s *Server) update(uri, text string) {
+	lines := strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n")
+	s.docs[uri] = lines
+	s.notify("textDocument/publishDiagnostics", PublishDiagnosticsParams{URI: uri, Diagnostics: diagnostics(lines)})
+}
+
+// line 返回文档中的一行，不存在时返回 false
+func (s *Server) line(p TextDocumentPositionParams) ([]string, string, bool) {
+	lines, ok := s.docs[p.TextDocument.URI]
+	if !ok || p.Position.Line < 0 || p.Position.Line >= len(lines) {
+		return nil, "", false
+	}
+	return lines, lines[p.Position.Line], true
+}
@@ -8,72 +8,83 @@
 package main
 
 import (
-	"flag"
-	"fmt"
 	"log"
 	"os"
+	"strings"
 
+	"github.com/bwb0101/goAnnotations/config"
 	"github.com/bwb0101/goAnnotations/generator"
 	"github.com/bwb0101/goAnnotations/generator/api"
 	codeModel "github.com/bwb0101/goAnnotations/generator/model"
-	"github.com/bwb0101/goAnnotations/model"
-	"github.com/bwb0101/goAnnotations/parser"
+	"github.com/bwb0101/goAnnotations/generator/openapi"
+	"github.com/bwb0101/goAnnotations/generator/tmpl"
+	"github.com/bwb0101/goAnnotations/logger"
 )
 
-const (
-	excludeMatchPattern = "^" + generator.GenfilePrefix + ".*.go$"
-)
-
-var (
-	dir         *string
-	mode        *string
-	pkgName     *string
-	static_func *bool
-)
-
-func main() {
-	processArgs()
-	// p := "test"
-	// pkgName = &p
-	// s := "D:\\Works\\github\\goAnnotations\\test"
-	// dir = &s
-	pkgs, _ := parser.ParseSourceDir(*dir, "^.*.go$", excludeMatchPattern)
-	// b, _ := json.MarshalIndent(pkgs, "", "\t")
-	// fmt.Println(string(b))
-	runAllGenerators(*dir, pkgs)
-}
+var registry = generator.NewRegistry()
 
-func runAllGenerators(inputDir string, parsedSources model.ParsedSources) {
-	parsedSources.PkgName = *pkgName
-	for name, g := range map[string]generator.Generator{
-		"api":   api.NewGeneratorApi(),
-		"model": codeModel.NewGeneratorModel(),
+func init() {
+	for _, e := range []generator.Entry{
+		{Name: "api", New: func(cfg config.Config) generator.Generator {
+			packageHttpBackends, packageMsgIdRanges := map[string]string{}, map[string]string{}
+			for pkgName, p := range cfg.Api.Packages {
+				packageHttpBackends[pkgName] = p.HttpBackend
+				packageMsgIdRanges[pkgName] = p.MsgIdRange
+			}
+			return api.NewGeneratorApi(api.Options{
+				NetFwImport:         cfg.Api.NetFwImport,
+				MultipartImport:     cfg.Api.MultipartImport,
+				HttpBackend:         cfg.Api.HttpBackend,
+				PackageHttpBackends: packageHttpBackends,
+				PackageMsgIdRanges:  packageMsgIdRanges,
+				Funcs:               api.FuncMode(cfg.Api.Funcs),
+			})
+		}},
+		{Name: "model", New: func(cfg config.Config) generator.Generator {
+			return codeModel.NewGeneratorModel(codeModel.Options{
+				Package:            cfg.Model.Package,
+				FrameworkLibImport: cfg.Model.FrameworkLibImport,
+				StorageImport:      cfg.Model.StorageImport,
+				Output:             cfg.Resolve(cfg.Model.Output),
+			})
+		}},
+		{Name: "templates", New: func(cfg config.Config) generator.Generator {
+			return tmpl.NewGeneratorTemplate(cfg.Resolve(cfg.TemplatesDir))
+		}},
+		{Name: "openapi", Optional: true, New: func(cfg config.Config) generator.Generator {
+			return openapi.NewGeneratorOpenApi(openapi.Options{
+				Output:      cfg.Resolve(cfg.OpenApi.Output),
+				Title:       cfg.OpenApi.Title,
+				Version:     cfg.OpenApi.Version,
+				TokenHeader: cfg.OpenApi.TokenHeader,
+				Recursive:   cfg.OpenApi.Recursive,
+			})
+		}},
 	} {
-		err := g.Generate(inputDir, parsedSources)
-		if err != nil {
-			log.Printf("Error generating module %s: %s", name, err)
-			os.Exit(-1)
+		if err := registry.Register(e); err != nil {
+			log.Fatal(err)
 		}
 	}
 }
 
-func processArgs() {
-	dir = flag.String("dir", "", "要检查的目录")
-	mode = flag.String("model", "", "检查模式")
-	pkgName = flag.String("pkg", "", "包名")
-	static_func = flag.Bool("static_func", false, "检查非struct的方法")
-
-	flag.Parse()
-
-	if dir == nil || *dir == "" {
+func main() {
+	log.SetFlags(0)
+	args := os.Args[1:]
+	if len(args) == 0 {
 		printUsage()
+		os.Exit(exitError)
 	}
-}
-
-func printUsage() {
-	_, _ = fmt.Fprintf(os.Stderr, "\n用法:\n")
-	_, _ = fmt.Fprintf(os.Stderr, " %s [flags]\n", os.Args[0])
-	flag.PrintDefaults()
-	_, _ = fmt.Fprintf(os.Stderr, "\n")
-	os.Exit(1)
+	if strings.HasPrefix(args[0], "-") && args[0] != "-h" && args[0] != "-help" && args[0] != "--help" {
+		// 兼容旧的 //go:generate goAnnotations -dir . 写法
+		os.Exit(runGenerate(args))
+	}
+	cmd, ok := lookupCommand(args[0])
+	if !ok {
+		if args[0] != "-h" && args[0] != "-help" && args[0] != "--help" {
+			logger.Errorf("unknown command %s", args[0])
+		}
+		printUsage()
+		os.Exit(exitError)
+	}
+	os.Exit(cmd.run(args[1:]))
 }
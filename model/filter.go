@@ -0,0 +1,65 @@
+package model
+
+import (
+	"slices"
+
+	"github.com/bwb0101/goAnnotations/annotation"
+)
+
+// 元素种类，与 JSON 中的字段名对应
+const (
+	KindStruct    = "struct"
+	KindOperation = "operation"
+	KindInterface = "interface"
+	KindTypedef   = "typedef"
+	KindEnum      = "enum"
+)
+
+var Kinds = []string{KindStruct, KindOperation, KindInterface, KindTypedef, KindEnum}
+
+// Filter 过滤条件，为空的条件不过滤
+type Filter struct {
+	Packages   []string
+	Kinds      []string
+	Annotation string // 带 @，只检查元素自身的文档注释
+}
+
+// Filter 只保留满足 f 的元素
+func (ps ParsedSources) Filter(f Filter) ParsedSources {
+	match := func(kind, pkg string, docLines []string) bool {
+		if len(f.Kinds) > 0 && !slices.Contains(f.Kinds, kind) {
+			return false
+		}
+		if len(f.Packages) > 0 && !slices.Contains(f.Packages, pkg) {
+			return false
+		}
+		return f.Annotation == "" || annotation.Has(docLines, f.Annotation)
+	}
+	filtered := ParsedSources{PkgName: ps.PkgName}
+	for _, s := range ps.Structs {
+		if match(KindStruct, s.PackageName, s.DocLines) {
+			filtered.Structs = append(filtered.Structs, s)
+		}
+	}
+	for _, o := range ps.Operations {
+		if match(KindOperation, o.PackageName, o.DocLines) {
+			filtered.Operations = append(filtered.Operations, o)
+		}
+	}
+	for _, i := range ps.Interfaces {
+		if match(KindInterface, i.PackageName, i.DocLines) {
+			filtered.Interfaces = append(filtered.Interfaces, i)
+		}
+	}
+	for _, t := range ps.Typedefs {
+		if match(KindTypedef, t.PackageName, t.DocLines) {
+			filtered.Typedefs = append(filtered.Typedefs, t)
+		}
+	}
+	for _, e := range ps.Enums {
+		if match(KindEnum, e.PackageName, e.DocLines) {
+			filtered.Enums = append(filtered.Enums, e)
+		}
+	}
+	return filtered
+}
@@ -2,6 +2,9 @@ package model
 
 import (
 	"fmt"
+	"go/ast"
+	"path/filepath"
+	"reflect"
 	"regexp"
 	"strings"
 )
@@ -25,6 +28,49 @@ func (f Field) EmptyInstance() string {
 	return fmt.Sprintf("%s{}", f.TypeName)
 }
 
+// JsonName encoding/json 中字段的名字: 有 json tag 时取 tag 中的名字，json:"-" 和未导出的字段为空；
+// 没有 tag 的嵌入字段为空、embedded 为 true，它的字段按 encoding/json 的规则展开到外层
+func (f Field) JsonName() (name string, embedded bool) {
+	name, _, _ = strings.Cut(reflect.StructTag(strings.Trim(f.Tag, "`")).Get("json"), ",")
+	switch {
+	case name == "-":
+		return "", false
+	case f.Name == "" && name == "":
+		return "", true
+	case f.Name != "" && !ast.IsExported(f.Name):
+		return "", false
+	case name == "":
+		return f.Name, false
+	}
+	return name, false
+}
+
+// FindStruct 在 structs 中查找 pkg.name(pkg 为空时不限包名)；同名的包有多个时选目录与 hint(import 路径或目录)结尾相同部分最多的
+func FindStruct(structs []Struct, pkg, name, hint string) *Struct {
+	var found *Struct
+	best := -1
+	for i := range structs {
+		st := &structs[i]
+		if st.Name != name || (pkg != "" && st.PackageName != pkg) {
+			continue
+		}
+		if score := commonSuffix(filepath.ToSlash(filepath.Dir(st.Filename)), filepath.ToSlash(hint)); score > best {
+			found, best = st, score
+		}
+	}
+	return found
+}
+
+// commonSuffix a、b 结尾相同的路径段数
+func commonSuffix(a, b string) int {
+	as, bs := strings.Split(a, "/"), strings.Split(b, "/")
+	n := 0
+	for n < len(as) && n < len(bs) && as[len(as)-1-n] == bs[len(bs)-1-n] {
+		n++
+	}
+	return n
+}
+
 func (f Field) DereferencedTypeName() string {
 	return strings.TrimPrefix(f.TypeName, "*")
 }
@@ -4,12 +4,13 @@ package model
 
 // @JsonStruct()
 type ParsedSources struct {
-	Structs    []Struct    `json:"structs,omitempty"`
-	Operations []Operation `json:"operations,omitempty"`
-	Interfaces []Interface `json:"interfaces,omitempty"`
-	Typedefs   []Typedef   `json:"typedefs,omitempty"`
-	Enums      []Enum      `json:"enums,omitempty"`
-	PkgName    string      `json:"-"`
+	SchemaVersion int         `json:"schemaVersion,omitempty"` // 见 SchemaVersion，输出 json 时自动填写
+	Structs       []Struct    `json:"structs,omitempty"`
+	Operations    []Operation `json:"operations,omitempty"`
+	Interfaces    []Interface `json:"interfaces,omitempty"`
+	Typedefs      []Typedef   `json:"typedefs,omitempty"`
+	Enums         []Enum      `json:"enums,omitempty"`
+	PkgName       string      `json:"-"`
 }
 
 // @JsonStruct()
@@ -17,6 +18,7 @@ type Operation struct {
 	PackageName   string   `json:"packageName,omitempty"`
 	Filename      string   `json:"filename,omitempty"`
 	DocLines      []string `json:"docLines,omitempty"`
+	DocLine       int      `json:"docLine,omitempty"`       // DocLines 第一行所在的行号，从 1 开始
 	RelatedStruct *Field   `json:"relatedStruct,omitempty"` // optional
 	Name          string   `json:"name"`
 	InputArgs     []Field  `json:"inputArgs,omitempty"`
@@ -29,6 +31,7 @@ type Struct struct {
 	PackageName  string       `json:"packageName"`
 	Filename     string       `json:"filename"`
 	DocLines     []string     `json:"docLines,omitempty"`
+	DocLine      int          `json:"docLine,omitempty"`
 	Name         string       `json:"name"`
 	Fields       []Field      `json:"fields,omitempty"`
 	Operations   []*Operation `json:"operations,omitempty"`
@@ -40,6 +43,7 @@ type Interface struct {
 	PackageName  string      `json:"packageName"`
 	Filename     string      `json:"filename"`
 	DocLines     []string    `json:"docLines,omitempty"`
+	DocLine      int         `json:"docLine,omitempty"`
 	Name         string      `json:"name"`
 	Methods      []Operation `json:"methods,omitempty"`
 	CommentLines []string    `json:"commentLines,omitempty"`
@@ -60,6 +64,7 @@ type Typedef struct {
 	PackageName string   `json:"packageName"`
 	Filename    string   `json:"filename"`
 	DocLines    []string `json:"docLines,omitempty"`
+	DocLine     int      `json:"docLine,omitempty"`
 	Name        string   `json:"name"`
 	Type        string   `json:"type,omitempty"`
 }
@@ -69,6 +74,7 @@ type Enum struct {
 	PackageName  string        `json:"packageName"`
 	Filename     string        `json:"filename"`
 	DocLines     []string      `json:"docLines,omitempty"`
+	DocLine      int           `json:"docLine,omitempty"`
 	Name         string        `json:"name,omitempty"`
 	EnumLiterals []EnumLiteral `json:"enumLiterals,omitempty"`
 	CommentLines []string      `json:"commentLines,omitempty"`
@@ -8,21 +8,28 @@ import (
 	"os"
 )
 
+// Parse 读取 json 格式的 ParsedSources，filename 为空或 "-" 时读 stdin
 func Parse(filename string) (ParsedSources, error) {
 	parsedSources := ParsedSources{}
 
 	var reader io.Reader = bufio.NewReader(os.Stdin)
-	if filename != "" {
+	source := "stdin"
+	if filename != "" && filename != "-" {
 		fp, err := os.Open(filename)
 		if err != nil {
 			return ParsedSources{}, fmt.Errorf("Error opening file %s: %s", filename, err)
 		}
+		defer fp.Close()
 		reader = bufio.NewReader(fp)
+		source = filename
 	}
 
 	err := json.NewDecoder(reader).Decode(&parsedSources)
 	if err != nil {
-		return ParsedSources{}, fmt.Errorf("Error decoding parsed-sources from stdin: %s", err)
+		return ParsedSources{}, fmt.Errorf("Error decoding parsed-sources from %s: %s", source, err)
+	}
+	if err = parsedSources.checkSchemaVersion(); err != nil {
+		return ParsedSources{}, fmt.Errorf("Error decoding parsed-sources from %s: %s", source, err)
 	}
 	return parsedSources, nil
 }
@@ -0,0 +1,90 @@
+package model
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// SchemaVersion ParsedSources json 格式的版本，删除/改名字段或改变含义时加 1，只增加字段时不变
+const SchemaVersion = 1
+
+// MarshalJSON 总是带上 schemaVersion，插件可以据此判断是否兼容
+func (ps ParsedSources) MarshalJSON() ([]byte, error) {
+	type parsedSources ParsedSources // 没有 MarshalJSON 方法，避免递归
+	if ps.SchemaVersion == 0 {
+		ps.SchemaVersion = SchemaVersion
+	}
+	return json.Marshal(parsedSources(ps))
+}
+
+// checkSchemaVersion 没有版本的 json 来自旧版本，按版本 1 处理
+func (ps ParsedSources) checkSchemaVersion() error {
+	if ps.SchemaVersion > SchemaVersion {
+		return fmt.Errorf("unsupported schema version %d (supported: %d)", ps.SchemaVersion, SchemaVersion)
+	}
+	return nil
+}
+
+// JsonSchema 由 ParsedSources 及其引用的类型生成的 JSON Schema (draft 2020-12)
+func JsonSchema() map[string]any {
+	defs := map[string]any{}
+	root := schemaFor(reflect.TypeOf(ParsedSources{}), defs)
+	return map[string]any{
+		"$schema":       "https://json-schema.org/draft/2020-12/schema",
+		"title":         "goAnnotations ParsedSources",
+		"description":   fmt.Sprintf("goAnnotations parse/inspect -format json 的输出，也是插件 Request 中的 parsedSources。schemaVersion %d", SchemaVersion),
+		"schemaVersion": SchemaVersion,
+		"$ref":          root["$ref"],
+		"$defs":         defs,
+	}
+}
+
+// schemaFor struct 放到 defs 中，返回对它的引用
+func schemaFor(t reflect.Type, defs map[string]any) map[string]any {
+	switch t.Kind() {
+	case reflect.Pointer:
+		return schemaFor(t.Elem(), defs)
+	case reflect.Slice:
+		return map[string]any{"type": "array", "items": schemaFor(t.Elem(), defs)}
+	case reflect.String:
+		return map[string]any{"type": "string"}
+	case reflect.Bool:
+		return map[string]any{"type": "boolean"}
+	case reflect.Int, reflect.Int64, reflect.Int32:
+		return map[string]any{"type": "integer"}
+	case reflect.Struct:
+		ref := map[string]any{"$ref": "#/$defs/" + t.Name()}
+		if _, ok := defs[t.Name()]; ok {
+			return ref
+		}
+		def := map[string]any{"type": "object"}
+		defs[t.Name()] = def // 先占位，Operation 和 Struct 互相引用
+		properties := map[string]any{}
+		required := []string{}
+		for i := 0; i < t.NumField(); i++ {
+			f := t.Field(i)
+			name, opts, _ := strings.Cut(f.Tag.Get("json"), ",")
+			if name == "-" || !f.IsExported() {
+				continue
+			}
+			if name == "" {
+				name = f.Name
+			}
+			properties[name] = schemaFor(f.Type, defs)
+			if !strings.Contains(opts, "omitempty") {
+				required = append(required, name)
+			}
+		}
+		if t == reflect.TypeOf(ParsedSources{}) {
+			properties["schemaVersion"] = map[string]any{"type": "integer", "minimum": 1, "maximum": SchemaVersion}
+		}
+		def["properties"] = properties
+		if len(required) > 0 {
+			def["required"] = required
+		}
+		return ref
+	}
+	panic(fmt.Sprintf("schemaFor: unsupported type %s", t))
+}
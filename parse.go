@@ -0,0 +1,45 @@
+package main
+
+import (
+	"io"
+	"os"
+
+	"github.com/bwb0101/goAnnotations/generator"
+	"github.com/bwb0101/goAnnotations/logger"
+	"github.com/bwb0101/goAnnotations/parser"
+)
+
+// runParse 只解析一次，输出的 json 可以给多次 generate -from 或其他工具使用
+func runParse(args []string) int {
+	fs := newFlagSet("parse", "[-dir <目录>] [-r] [-o <model.json>]")
+	parseDir := fs.String("dir", ".", "要解析的目录")
+	recursive := fs.Bool("r", false, "包括子目录")
+	output := fs.String("o", "-", "输出文件，- 为 stdout")
+	_ = fs.Parse(args)
+
+	parse := parser.ParseSourceDir
+	if *recursive {
+		parse = parser.ParseSourceTree
+	}
+	parsedSources, err := parse(*parseDir, "^.*.go$", generator.GenfileExcludePattern)
+	if err != nil {
+		logger.Errorf("Error parsing %s: %s", *parseDir, err)
+		return exitError
+	}
+
+	var w io.Writer = os.Stdout
+	if *output != "-" {
+		f, err := os.Create(*output)
+		if err != nil {
+			logger.Errorf("Error creating %s: %s", *output, err)
+			return exitError
+		}
+		defer f.Close()
+		w = f
+	}
+	if err = inspectJson(w, parsedSources); err != nil {
+		logger.Errorf("Error writing %s: %s", *output, err)
+		return exitError
+	}
+	return exitOK
+}
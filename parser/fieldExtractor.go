@@ -3,10 +3,10 @@ package parser
 import (
 	"fmt"
 	"go/ast"
-	"log"
 	"reflect"
 	"strings"
 
+	"github.com/bwb0101/goAnnotations/logger"
 	"github.com/bwb0101/goAnnotations/model"
 )
 
@@ -88,7 +88,7 @@ func processExpression(expr ast.Expr, imports map[string]string) *Expression {
 		return mExpr
 	}
 
-	log.Printf("*** Could not understand expression %+v", reflect.TypeOf(expr))
+	logger.Debugf("*** Could not understand expression %+v", reflect.TypeOf(expr))
 	return nil
 }
 
@@ -11,11 +11,14 @@ import (
 	"go/ast"
 	"go/parser"
 	"go/token"
-	"log"
+	"io/fs"
 	"os"
+	"path/filepath"
 	"regexp"
 	"sort"
+	"strings"
 
+	"github.com/bwb0101/goAnnotations/logger"
 	"github.com/bwb0101/goAnnotations/model"
 )
 
@@ -39,12 +42,13 @@ func (list fileEntries) Swap(i, j int) {
 }
 
 func ParseSourceDir(dirName string, includeRegex string, excludeRegex string) (model.ParsedSources, error) {
-	packages, err := parseDir(dirName, includeRegex, excludeRegex)
+	packages, fileSet, err := parseDir(dirName, includeRegex, excludeRegex)
 	if err != nil {
-		log.Printf("error parsing dir %s: %s", dirName, err.Error())
+		logger.Debugf("error parsing dir %s: %s", dirName, err.Error())
 		return model.ParsedSources{}, err
 	}
 	v := &astVisitor{
+		fileSet: fileSet,
 		Imports: map[string]string{},
 	}
 	for _, aPackage := range packages {
@@ -64,7 +68,34 @@ func ParseSourceDir(dirName string, includeRegex string, excludeRegex string) (m
 	}, nil
 }
 
-func parseDir(dirName string, includeRegex string, excludeRegex string) (map[string]*ast.Package, error) {
+// ParseSourceTree 递归解析 rootDir 及其子目录(跳过 vendor、testdata 和隐藏目录)
+func ParseSourceTree(rootDir string, includeRegex string, excludeRegex string) (model.ParsedSources, error) {
+	parsedSources := model.ParsedSources{}
+	err := filepath.WalkDir(rootDir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.IsDir() {
+			return nil
+		}
+		if name := d.Name(); path != rootDir && (name == "vendor" || name == "testdata" || strings.HasPrefix(name, ".")) {
+			return filepath.SkipDir
+		}
+		sources, err := ParseSourceDir(path, includeRegex, excludeRegex)
+		if err != nil {
+			return err
+		}
+		parsedSources.Structs = append(parsedSources.Structs, sources.Structs...)
+		parsedSources.Operations = append(parsedSources.Operations, sources.Operations...)
+		parsedSources.Interfaces = append(parsedSources.Interfaces, sources.Interfaces...)
+		parsedSources.Typedefs = append(parsedSources.Typedefs, sources.Typedefs...)
+		parsedSources.Enums = append(parsedSources.Enums, sources.Enums...)
+		return nil
+	})
+	return parsedSources, err
+}
+
+func parseDir(dirName string, includeRegex string, excludeRegex string) (map[string]*ast.Package, *token.FileSet, error) {
 	var includePattern = regexp.MustCompile(includeRegex)
 	var excludePattern = regexp.MustCompile(excludeRegex)
 
@@ -76,11 +107,11 @@ func parseDir(dirName string, includeRegex string, excludeRegex string) (map[str
 		return includePattern.MatchString(fi.Name())
 	}, parser.ParseComments)
 	if err != nil {
-		log.Printf("error parsing dir %s: %s", dirName, err.Error())
-		return packageMap, err
+		logger.Debugf("error parsing dir %s: %s", dirName, err.Error())
+		return packageMap, fileSet, err
 	}
 
-	return packageMap, nil
+	return packageMap, fileSet, nil
 }
 
 func parsePackage(aPackage *ast.Package, v *astVisitor) {
@@ -138,6 +169,7 @@ func embedTypedefDocLinesInEnum(visitor *astVisitor) {
 		for _, typedef := range visitor.Typedefs {
 			if typedef.Name == mEnum.Name {
 				visitor.Enums[idx].DocLines = typedef.DocLines
+				visitor.Enums[idx].DocLine = typedef.DocLine
 				break
 			}
 		}
@@ -9,6 +9,7 @@ package parser
 
 import (
 	"go/ast"
+	"go/token"
 	"path/filepath"
 	"strings"
 
@@ -16,6 +17,7 @@ import (
 )
 
 type astVisitor struct {
+	fileSet         *token.FileSet
 	CurrentFilename string
 	PackageName     string
 	Filename        string
@@ -48,6 +50,21 @@ func (v *astVisitor) Visit(node ast.Node) ast.Visitor {
 	return v
 }
 
+// docLine 声明的文档注释所在的行，没有文档注释时为 0
+func (v *astVisitor) docLine(node ast.Node) int {
+	var doc *ast.CommentGroup
+	switch n := node.(type) {
+	case *ast.GenDecl:
+		doc = n.Doc
+	case *ast.FuncDecl:
+		doc = n.Doc
+	}
+	if doc == nil || v.fileSet == nil {
+		return 0
+	}
+	return v.fileSet.Position(doc.Pos()).Line
+}
+
 func (v *astVisitor) extractGenDeclImports(node ast.Node) {
 	if genDecl, ok := node.(*ast.GenDecl); ok {
 		for _, spec := range genDecl.Specs {
@@ -69,6 +86,7 @@ func (v *astVisitor) parseAsStruct(node ast.Node) {
 		for _, mStruct := range mStructs {
 			mStruct.PackageName = v.PackageName
 			mStruct.Filename = v.CurrentFilename
+			mStruct.DocLine = v.docLine(node)
 			v.Structs = append(v.Structs, *mStruct)
 		}
 	}
@@ -78,6 +96,7 @@ func (v *astVisitor) parseAsTypedef(node ast.Node) {
 	if mTypedef := extractGenDeclForTypedef(node); mTypedef != nil {
 		mTypedef.PackageName = v.PackageName
 		mTypedef.Filename = v.CurrentFilename
+		mTypedef.DocLine = v.docLine(node)
 		v.Typedefs = append(v.Typedefs, *mTypedef)
 	}
 }
@@ -86,6 +105,7 @@ func (v *astVisitor) parseAsEnum(node ast.Node) {
 	if mEnum := extractGenDeclForEnum(node); mEnum != nil {
 		mEnum.PackageName = v.PackageName
 		mEnum.Filename = v.CurrentFilename
+		mEnum.DocLine = v.docLine(node)
 		v.Enums = append(v.Enums, *mEnum)
 	}
 }
@@ -95,6 +115,7 @@ func (v *astVisitor) parseAsInterFace(node ast.Node) {
 	if mInterface := extractInterface(node, v.Imports); mInterface != nil {
 		mInterface.PackageName = v.PackageName
 		mInterface.Filename = v.CurrentFilename
+		mInterface.DocLine = v.docLine(node)
 		v.Interfaces = append(v.Interfaces, *mInterface)
 	}
 }
@@ -104,6 +125,7 @@ func (v *astVisitor) parseAsOperation(node ast.Node) {
 	if mOperation := extractOperation(node, v.Imports); mOperation != nil {
 		mOperation.PackageName = v.PackageName
 		mOperation.Filename = v.CurrentFilename
+		mOperation.DocLine = v.docLine(node)
 		v.Operations = append(v.Operations, *mOperation)
 	}
 }
@@ -0,0 +1,34 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+
+	"github.com/bwb0101/goAnnotations/logger"
+	"github.com/bwb0101/goAnnotations/model"
+)
+
+// runSchema 输出 model.ParsedSources 的 JSON Schema，给读取 parse 输出的插件和脚本使用
+func runSchema(args []string) int {
+	fs := newFlagSet("schema", "[-o <schema.json>]")
+	output := fs.String("o", "-", "输出文件，- 为 stdout")
+	_ = fs.Parse(args)
+
+	b, err := json.MarshalIndent(model.JsonSchema(), "", "\t")
+	if err != nil {
+		logger.Errorf("Error encoding schema: %s", err)
+		return exitError
+	}
+	b = append(b, '\n')
+	if *output == "-" {
+		_, err = os.Stdout.Write(b)
+	} else {
+		err = os.WriteFile(*output, b, 0644)
+	}
+	if err != nil {
+		logger.Errorf("Error writing %s: %s", *output, err)
+		return exitError
+	}
+	logger.Debugf("Schema version %d", model.SchemaVersion)
+	return exitOK
+}
@@ -0,0 +1,49 @@
+// Code generated by goAnnotations. DO NOT EDIT.
+// Generator: api
+// Sources: user.go
+
+/*
+API注册(fasthttp router)
+@Handler(type="api", net="http", method="GET|POST", path="/user/{id}", params="id:int64") (httpBackend 为 nethttp/fasthttp)
+path = /xxx：请求路径，{name} 为路径参数；net=http有效
+method = GET|POST：允许的请求方法，| 分隔，不写时不限制；net=http 且 httpBackend 为 nethttp/fasthttp 时有效
+params = id:int64,name：路径参数的类型(string/int/int64/uint64/bool)，不写为 string；生成的代码解析后传给 handler；net=http 且 httpBackend 为 nethttp/fasthttp 时有效
+validation = token：需要验证token(net=http)；user：检测Connect->UserValue是否为nil(net=tcp/udp)；空或不写：忽略验证
+有路径参数时 handler 为 func(*fasthttp.RequestCtx, 参数...)，解析失败返回 400
+validation="token" 的接口经过 tokenValidator 验证
+不支持 bodyLimit(由 fasthttp.Server.MaxRequestBodySize 统一限制)、resp、dataPtrStruct、bodyType
+*/
+
+package user
+
+import (
+	"github.com/fasthttp/router"
+	"github.com/valyala/fasthttp"
+	"strconv"
+)
+
+// RegisterHttpHandlers 把本包的 HTTP 接口注册到 r，tokenValidator 用于 validation="token" 的接口
+func RegisterHttpHandlers(r *router.Router, tokenValidator func(fasthttp.RequestHandler) fasthttp.RequestHandler) {
+	r.Handle("GET", "/user/{id}", fasthttp.RequestHandler(func(ctx *fasthttp.RequestCtx) {
+		id, err := strconv.ParseUint(ctx.UserValue("id").(string), 10, 64)
+		if err != nil {
+			ctx.Error("invalid path parameter id", fasthttp.StatusBadRequest)
+			return
+		}
+		GetUser(ctx, id)
+	}))
+	{
+		h := fasthttp.RequestHandler(func(ctx *fasthttp.RequestCtx) {
+			id, err := strconv.Atoi(ctx.UserValue("id").(string))
+			if err != nil {
+				ctx.Error("invalid path parameter id", fasthttp.StatusBadRequest)
+				return
+			}
+			tag := ctx.UserValue("tag").(string)
+			DeleteTag(ctx, id, tag)
+		})
+		r.Handle("DELETE", "/user/{id}/tag/{tag}", h)
+		r.Handle("POST", "/user/{id}/tag/{tag}", h)
+	}
+	r.Handle(router.MethodWild, "/ping", fasthttp.RequestHandler(Ping))
+}
@@ -0,0 +1,12 @@
+package user
+
+import "github.com/valyala/fasthttp"
+
+// @Handler(type="api", net="http", method="GET", path="/user/{id}", params="id:uint64")
+func GetUser(ctx *fasthttp.RequestCtx, id uint64) {}
+
+// @Handler(type="api", net="http", method="DELETE|POST", path="/user/{id}/tag/{tag}", params="tag,id:int")
+func DeleteTag(ctx *fasthttp.RequestCtx, id int, tag string) {}
+
+// @Handler(type="api", net="http", path="/ping")
+func Ping(ctx *fasthttp.RequestCtx) {}
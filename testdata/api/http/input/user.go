@@ -0,0 +1,9 @@
+package user
+
+// @Handler(type="api", net="http", path="/register", bodyLimit=2, resp="object", validation="token", dataPtrStruct="example/dto|dto.RegisterReq")
+func Register() {}
+
+// @Handler(type="api", net="http", path="/avatar")
+// @Handler(type="valid.limit", pkg="example/limit", func="limit.PerMinute")
+// @Handler(type="valid.file", pkg="example/upload", func="upload.IsImage", headsize=8)
+func Avatar() {}
@@ -0,0 +1,15 @@
+package user
+
+import "net/http"
+
+// @Handler(type="api", net="http", path="/a)
+func Unterminated(w http.ResponseWriter, r *http.Request) {}
+
+// @Handler(type="api", net="http", path="/b\q")
+func BadEscape(w http.ResponseWriter, r *http.Request) {}
+
+// @Handler(type="api", net="http" path="/c")
+func MissingComma(w http.ResponseWriter, r *http.Request) {}
+
+// @Handler(type="api", net="http", path="/ok")
+func Ok(w http.ResponseWriter, r *http.Request) {}
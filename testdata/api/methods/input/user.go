@@ -0,0 +1,25 @@
+package user
+
+// @Service(constructor="NewUserService")
+type UserService struct {
+	db string
+}
+
+func NewUserService() *UserService {
+	return &UserService{db: "user"}
+}
+
+// @Handler(type="api", net="http", path="/user/login", resp="object", dataPtrStruct="example/dto|dto.LoginReq")
+func (s *UserService) Login() {}
+
+// @Handler(type="api", net="tcp", msgId="1001", validation="user")
+func (s *UserService) Heartbeat() {}
+
+// @Service()
+type AdminService struct{}
+
+// @Handler(type="api", net="http", path="/admin/login", validation="token")
+func (AdminService) Login() {}
+
+// @Handler(type="api", net="http", path="/ping")
+func Ping() {}
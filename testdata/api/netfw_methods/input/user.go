@@ -0,0 +1,10 @@
+package user
+
+import "net/http"
+
+// GetUser 不指定 httpBackend 时为 netfw，不支持 method 和 params
+// @Handler(type="api", net="http", method="GET", path="/user/{id}", params="id:int64")
+func GetUser(w http.ResponseWriter, r *http.Request, id int64) {}
+
+// @Handler(type="api", net="http", path="/ping")
+func Ping(w http.ResponseWriter, r *http.Request) {}
@@ -0,0 +1,7 @@
+package user
+
+// @Handler(type="api", net="http", path="/register", bodyLimit=2, validation="token")
+func Register() {}
+
+// @Handler(type="api", net="http", path="/ping")
+func Ping() {}
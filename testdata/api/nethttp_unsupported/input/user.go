@@ -0,0 +1,13 @@
+package user
+
+import "net/http"
+
+// Login nethttp 的 handler 自己处理请求体，resp 和 dataPtrStruct 报错而不是被忽略
+// @Handler(type="api", net="http", path="/login", resp="object")
+func Login(w http.ResponseWriter, r *http.Request) {}
+
+// @Handler(type="api", net="http", path="/register", dataPtrStruct="example/dto|dto.RegisterReq")
+func Register(w http.ResponseWriter, r *http.Request) {}
+
+// @Handler(type="api", net="http", path="/ping", bodyType="0")
+func Ping(w http.ResponseWriter, r *http.Request) {}
@@ -0,0 +1,53 @@
+// Code generated by goAnnotations. DO NOT EDIT.
+// Generator: api
+// Sources: user.go
+
+/*
+API注册(net/http)
+@Handler(type="api", net="http", method="GET|POST", path="/user/{id}", params="id:int64") (httpBackend 为 nethttp/fasthttp)
+path = /xxx：请求路径，{name} 为路径参数；net=http有效
+method = GET|POST：允许的请求方法，| 分隔，不写时不限制；net=http 且 httpBackend 为 nethttp/fasthttp 时有效
+params = id:int64,name：路径参数的类型(string/int/int64/uint64/bool)，不写为 string；生成的代码解析后传给 handler；net=http 且 httpBackend 为 nethttp/fasthttp 时有效
+bodyLimit = n：当前请求体限制(k) 0 = 默认服务器配置；net=http有效
+validation = token：需要验证token(net=http)；user：检测Connect->UserValue是否为nil(net=tcp/udp)；空或不写：忽略验证
+有路径参数时 handler 为 func(http.ResponseWriter, *http.Request, 参数...)，解析失败返回 400
+没有默认的服务器配置，bodyLimit 为 0 时不限制；validation="token" 的接口经过 tokenValidator 验证
+不支持 resp、dataPtrStruct、bodyType，handler 自己处理请求体
+*/
+
+package user
+
+import (
+	"net/http"
+	"strconv"
+)
+
+// RegisterHttpHandlers 把本包的 HTTP 接口注册到 mux，tokenValidator 用于 validation="token" 的接口
+func RegisterHttpHandlers(mux *http.ServeMux, tokenValidator func(http.Handler) http.Handler) {
+	mux.Handle("GET /user/{id}", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+		if err != nil {
+			http.Error(w, "invalid path parameter id", http.StatusBadRequest)
+			return
+		}
+		GetUser(w, r, id)
+	}))
+	{
+		h := tokenValidator(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+			if err != nil {
+				http.Error(w, "invalid path parameter id", http.StatusBadRequest)
+				return
+			}
+			name := r.PathValue("name")
+			Rename(w, r, id, name)
+		}))
+		mux.Handle("PUT /user/{id}/name/{name}", h)
+		mux.Handle("PATCH /user/{id}/name/{name}", h)
+	}
+	{
+		h := http.HandlerFunc(Ping)
+		mux.Handle("GET /ping", h)
+		mux.Handle("POST /ping", h)
+	}
+}
@@ -0,0 +1,12 @@
+package user
+
+import "net/http"
+
+// @Handler(type="api", net="http", method="GET", path="/user/{id}", params="id:int64")
+func GetUser(w http.ResponseWriter, r *http.Request, id int64) {}
+
+// @Handler(type="api", net="http", method="PUT|PATCH", path="/user/{id}/name/{name}", params="id:int64", validation="token")
+func Rename(w http.ResponseWriter, r *http.Request, id int64, name string) {}
+
+// @Handler(type="api", net="http", method="GET|POST", path="/ping")
+func Ping(w http.ResponseWriter, r *http.Request) {}
@@ -0,0 +1,37 @@
+// Code generated by goAnnotations. DO NOT EDIT.
+// Generator: api
+// Sources: user.go
+
+/*
+API注册
+@Handler(type="api", net="http", path="/reg", bodyLimit=n, resp="object", validation="token", dataPtrStruct="path|pkg.struct", bodyType="0/1")
+net = http/tcp/udp：根据net类型分类处理
+path = /xxx：请求路径，{name} 为路径参数；net=http有效
+bodyLimit = n：当前请求体限制(k) 0 = 默认服务器配置；net=http有效
+resp = object：返回的对象需要进行序列化；net=http有效
+validation = token：需要验证token(net=http)；user：检测Connect->UserValue是否为nil(net=tcp/udp)；空或不写：忽略验证
+dataPtrStruct = path|pkg.struct：path = import的路径；pkg.struct = 反序列化时的包名结构体
+bodyType = 0/1：默认0，1 framebody类型
+
+访问限制
+@Handler(type="valid.limit", pkg="", func="")
+pkg = 包名: xxx/xxx；type=valid.limit/valid.file有效
+func = 方法名: xxx.xxx；type=valid.limit/valid.file有效
+
+上传文件时验证文件头是否合法
+@Handler(type="valid.file", pkg="", func="", headsize=n)
+pkg = 包名: xxx/xxx；type=valid.limit/valid.file有效
+func = 方法名: xxx.xxx；type=valid.limit/valid.file有效
+headsize = 验证文件头大小: body[:headsize]；type=valid.file有效
+*/
+
+package user
+
+import (
+	net_fw "framework/common/net_fw"
+)
+
+func init() {
+	net_fw.NewHtNetHandler(net_fw.HTNET_type_http, "/a,b?x=1", false, net_fw.Validation_type_none, 0, Search, "Search", nil, nil, nil, 0)
+	net_fw.NewHtNetHandler(net_fw.HTNET_type_http, "/say/\"hi\"/c:\\dir", false, net_fw.Validation_type_none, 0, Quote, "Quote", nil, nil, nil, 0)
+}
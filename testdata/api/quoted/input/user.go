@@ -0,0 +1,14 @@
+package user
+
+import "net/http"
+
+// Search 值中的逗号、= 和 ? 在引号内不拆分
+// @Handler(type="api", net="http", path="/a,b?x=1")
+func Search(w http.ResponseWriter, r *http.Request) {}
+
+// Quote 转义的引号和反斜杠
+// @Handler(type="api", net="http", path="/say/\"hi\"/c:\\dir")
+func Quote(w http.ResponseWriter, r *http.Request) {}
+
+// @Handler(type="api", net="tcp", msgId="7", dataPtrStruct="example/pb|pb.Login")
+func Login() {}
@@ -0,0 +1,23 @@
+// Code generated by goAnnotations. DO NOT EDIT.
+// Generator: api
+// Sources: game.go
+
+/*
+API注册
+@Handler(type="api", net="tcp/udp", msgId="uint16", dataPtrStruct="path|pkg.struct", validation="user", bodyType="0/1")
+net = http/tcp/udp：根据net类型分类处理
+msgId = uint16数值；net=tcp/udp有效
+validation = token：需要验证token(net=http)；user：检测Connect->UserValue是否为nil(net=tcp/udp)；空或不写：忽略验证
+dataPtrStruct = path|pkg.struct：path = import的路径；pkg.struct = 反序列化时的包名结构体
+bodyType = 0/1：默认0，1 framebody类型
+*/
+
+package game
+
+import (
+	net_fw "framework/common/net_fw"
+)
+
+func init() {
+	net_fw.NewKcpNetHandler(20, Move, "Move", nil, false, 1)
+}
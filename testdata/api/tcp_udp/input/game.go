@@ -0,0 +1,10 @@
+package game
+
+// @Handler(type="api", net="tcp", msgId="1001", validation="user", dataPtrStruct="example/pb|pb.Login")
+func Login() {}
+
+// @Handler(type="api", net="tcp", msgId="6")
+func Ping() {}
+
+// @Handler(type="api", net="udp", msgId="20", bodyType="1")
+func Move() {}
@@ -0,0 +1,59 @@
+// Code generated by goAnnotations. DO NOT EDIT.
+// Generator: model
+// Sources: ranking.go
+
+package model_user
+
+import (
+	framework_lib "common/framework_lib"
+	storage "common/framework_lib/storage"
+)
+
+type tb_key struct {
+	TKey string
+	MKey string
+}
+type col_Ranking struct {
+	tb_key
+	TableName storage.ColumnTblName // 表名
+	Id        storage.ColumnTblField
+}
+type col_Ranking2 struct {
+	tb_key
+	TableName storage.ColumnTblName // 表名
+	N         storage.ColumnTblField
+}
+type colStruct struct {
+	Ranking  col_Ranking
+	Ranking2 col_Ranking2
+}
+
+var Columns = colStruct{
+	Ranking: col_Ranking{
+		TableName: "Ranking",
+		tb_key:    tb_key{"tb", "Id"},
+	},
+	Ranking2: col_Ranking2{
+		TableName: "Ranking2",
+		N:         "N",
+	},
+}
+
+type rankingDAO int8
+type ranking2DAO int8
+
+var DAO = struct {
+	Ranking  rankingDAO
+	Ranking2 ranking2DAO
+}{}
+
+func init() {
+	framework_lib.Framework.Store.SetColumnTblStruct(map[storage.ColumnTblName]func() storage.StorageModel{
+		Columns.Ranking.TableName: func() storage.StorageModel {
+			return &Ranking{}
+		},
+		Columns.Ranking2.TableName: func() storage.StorageModel {
+			return &Ranking2{}
+		},
+	})
+}
@@ -0,0 +1,32 @@
+package dto
+
+import "time"
+
+type Base struct {
+	RequestId string `json:"requestId"`
+}
+
+// RegisterReq 注册请求
+type RegisterReq struct {
+	Base
+	// 用户名
+	Name     string            `json:"name"`
+	Password string            `json:"password,omitempty"` // 明文密码
+	Age      int               `json:"age"`
+	Tags     []string          `json:"tags,omitempty"`
+	Extra    map[string]string `json:"extra,omitempty"`
+	Address  *Address          `json:"address,omitempty"`
+	Birthday time.Time         `json:"birthday"`
+	Secret   string            `json:"-"`
+	internal int
+	NoTag    bool
+}
+
+type Address struct {
+	City string  `json:"city"`
+	Lat  float64 `json:"lat"`
+}
+
+type Upload struct {
+	Data []byte `json:"data"`
+}
@@ -0,0 +1,16 @@
+package user
+
+// Register 注册新用户
+// 用户名已存在时返回错误
+// @Handler(type="api", net="http", method="POST", path="/register", bodyLimit=2, resp="object", validation="token", dataPtrStruct="example/dto|dto.RegisterReq")
+func Register() {}
+
+// GetUser 查询用户
+// @Handler(type="api", net="http", method="GET|HEAD", path="/user/{id}", params="id:int64", resp="object")
+func GetUser() {}
+
+// @Handler(type="api", net="http", path="/upload/{name}", dataPtrStruct="example/dto|dto.Upload", bodyType="1")
+func Upload() {}
+
+// @Handler(type="api", net="tcp", msgId="1")
+func Login() {}